@@ -1,21 +1,141 @@
 package apimate
 
 import (
+	"context"
 	"fmt"
 	"github.com/rollicks-c/apimate/internal/client"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
 type Option = client.RequestOption
 type JsonBool = client.JsonBool
 type JsonInt64 = client.JsonInt64
+type HTTPError = client.HTTPError
+type RetryPolicy = client.RetryPolicy
+type TokenSource = client.TokenSource
+type ConditionalInvalidator = client.ConditionalInvalidator
+type Authenticator = client.Authenticator
+type Challenger = client.Challenger
+type HMACConfig = client.HMACConfig
+type HMACCanonical = client.HMACCanonical
+type OAuth2Config = client.OAuth2Config
+type OAuth2TokenSource = client.OAuth2TokenSource
+type Token = client.Token
+type CookieJar = client.CookieJar
+type RedirectPolicy = client.RedirectPolicy
+type RateLimiter = client.RateLimiter
+type Paginator = client.Paginator
+type PageRanger = client.PageRanger
+type PageCountPaginator = client.PageCountPaginator
+type LinkPaginator = client.LinkPaginator
+type CursorPaginator = client.CursorPaginator
+type OffsetPaginator = client.OffsetPaginator
+
+const (
+	DefaultHMACTemplate = client.DefaultHMACTemplate
+
+	OAuth2AuthBasic = client.OAuth2AuthBasic
+	OAuth2AuthBody  = client.OAuth2AuthBody
+)
+
+var (
+	ErrRateLimited      = client.ErrRateLimited
+	ErrRetriesExhausted = client.ErrRetriesExhausted
+	ErrBadRequest       = client.ErrBadRequest
+	ErrUnauthorized     = client.ErrUnauthorized
+	ErrForbidden        = client.ErrForbidden
+	ErrNotFound         = client.ErrNotFound
+	ErrConflict         = client.ErrConflict
+	ErrClientError      = client.ErrClientError
+	ErrServerError      = client.ErrServerError
+	ErrRedirect         = client.ErrRedirect
+	ErrLoginFailed      = client.ErrLoginFailed
+	ErrSessionExpired   = client.ErrSessionExpired
+	ErrTimeout          = client.ErrTimeout
+	ErrPinMismatch      = client.ErrPinMismatch
+)
 
 func WithAllPages(pageParam, pagesHeader string) client.RequestOption {
+	return WithPaginator(client.PageCountPaginator{
+		PageParam:       pageParam,
+		PageCountHeader: pagesHeader,
+	})
+}
+
+func WithLinkHeaderPaging() client.RequestOption {
+	return WithPaginator(client.LinkPaginator{})
+}
+
+func WithCursorPaging(cursorPath, cursorParam, itemsPath string) client.RequestOption {
+	return WithPaginator(client.CursorPaginator{
+		CursorPath:  cursorPath,
+		CursorParam: cursorParam,
+		ItemsPath:   itemsPath,
+	})
+}
+
+// OffsetOption refines offset paging, see WithOffsetPaging
+type OffsetOption func(paginator *client.OffsetPaginator)
+
+// OffsetTotal reads the total item count from a header or a JSON path of the body, allowing concurrent paging
+func OffsetTotal(totalHeader, totalPath string) OffsetOption {
+	return func(paginator *client.OffsetPaginator) {
+		paginator.TotalHeader = totalHeader
+		paginator.TotalPath = totalPath
+	}
+}
+
+// OffsetItems reads a page's items from a JSON path of the body instead of its root
+func OffsetItems(itemsPath string) OffsetOption {
+	return func(paginator *client.OffsetPaginator) {
+		paginator.ItemsPath = itemsPath
+	}
+}
+
+func WithOffsetPaging(offsetParam, limitParam string, pageSize int, options ...OffsetOption) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		if pageSize <= 0 {
+			return fmt.Errorf("invalid page size: %d", pageSize)
+		}
+		paginator := client.OffsetPaginator{
+			OffsetParam: offsetParam,
+			LimitParam:  limitParam,
+			PageSize:    pageSize,
+		}
+		for _, option := range options {
+			option(&paginator)
+		}
+		ctx.Paginator = paginator
+		return nil
+	}
+}
+
+func WithConcurrentPages(concurrency int) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		if concurrency < 1 {
+			return fmt.Errorf("invalid page concurrency: %d", concurrency)
+		}
+		ctx.PageConcurrency = concurrency
+		return nil
+	}
+}
+
+func WithPaginator(paginator Paginator) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.Paginator = paginator
+		return nil
+	}
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return client.DefaultRetryPolicy()
+}
+
+func WithRetryPolicy(policy RetryPolicy) client.RequestOption {
 	return func(ctx *client.RequestContext) error {
-		ctx.Paging.ConsumeAll = true
-		ctx.Paging.PageParam = pageParam
-		ctx.Paging.PageCountHeader = pagesHeader
+		ctx.RetryPolicy = policy
 		return nil
 	}
 }
@@ -46,6 +166,9 @@ func WithStatusChecker(checker client.StatusChecker) client.RequestOption {
 type Client struct {
 	apiUrl         string
 	defaultOptions []client.RequestOption
+	transports     *client.TransportPool
+	jar            *client.CookieJar
+	login          *loginState
 }
 
 func New(apiUrl string, defaults ...client.RequestOption) *Client {
@@ -53,22 +176,108 @@ func New(apiUrl string, defaults ...client.RequestOption) *Client {
 	return &Client{
 		apiUrl:         apiUrl,
 		defaultOptions: defaults,
+		transports:     client.NewTransportPool(),
+		login:          &loginState{},
+	}
+}
+
+// NewSession creates a client keeping cookies across requests, persisted to sessionFile unless empty
+func NewSession(apiUrl, sessionFile string, defaults ...client.RequestOption) (*Client, error) {
+
+	jar, err := client.NewCookieJar(sessionFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c := New(apiUrl, defaults...)
+	c.jar = jar
+	return c, nil
+}
+
+func (c Client) Cookies() ([]*http.Cookie, error) {
+	if c.jar == nil {
+		return nil, nil
+	}
+	u, err := url.Parse(c.apiUrl)
+	if err != nil {
+		return nil, err
+	}
+	return c.jar.Cookies(u), nil
+}
+
+// SessionErr reports if the session file could not be written the last time cookies changed
+func (c Client) SessionErr() error {
+	if c.jar == nil {
+		return nil
+	}
+	return c.jar.Err()
+}
+
+func (c Client) ClearCookies() error {
+	if c.jar == nil {
+		return nil
+	}
+	return c.jar.Clear()
+}
+
+func (c Client) CloseIdleConnections() {
+	if c.transports != nil {
+		c.transports.CloseIdleConnections()
 	}
 }
 
 func (c Client) Request(method, ep string, options ...client.RequestOption) error {
+	return c.RequestCtx(context.Background(), method, ep, options...)
+}
+
+func (c Client) RequestCtx(ctx context.Context, method, ep string, options ...client.RequestOption) error {
+
+	// execute, signing in again once if the session expired
+	options, session := c.sessionOptions(options)
+	err := c.request(ctx, method, ep, options)
+	if err != nil {
+		var retry bool
+		if retry, err = c.relogin(ctx, session, err); retry {
+			err = c.request(ctx, method, ep, options)
+		}
+	}
+
+	return err
+}
+
+func (c Client) request(ctx context.Context, method, ep string, options []client.RequestOption) error {
+
+	// build runner
+	runner, err := c.newRunner(ctx, method, ep, options)
+	if err != nil {
+		return err
+	}
+
+	// execute
+	if err := runner.DoRequest(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+func (c Client) newRunner(ctx context.Context, method, ep string, options []client.RequestOption) (*client.RequestRunner, error) {
 
 	// create context with default options
-	ctx := &client.RequestContext{
+	reqCtx := &client.RequestContext{
+		Context:            ctx,
 		ApiUrl:             c.apiUrl,
 		Method:             method,
-		Endpoint:           fmt.Sprintf("%s/%s", strings.TrimSuffix(c.apiUrl, "/"), strings.TrimPrefix(ep, "/")),
+		Endpoint:           c.endpoint(ep),
 		AutoThrottle:       true,
-		AutoRetries:        3,
-		Paging:             client.PagingConfig{ConsumeAll: false},
+		RetryPolicy:        client.DefaultRetryPolicy(),
 		DefaultOptions:     c.defaultOptions,
 		ResponseProcessors: []client.ResponseProcessor{},
-		SkipTLSVerify:      false,
+		Transports:         c.transports,
+	}
+	if c.jar != nil {
+		reqCtx.Jar = c.jar
 	}
 
 	// apply defaults options
@@ -81,17 +290,14 @@ func (c Client) Request(method, ep string, options ...client.RequestOption) erro
 	// apply custom options
 	options = append(defaults, options...)
 	for _, option := range options {
-		if err := option(ctx); err != nil {
-			return err
+		if err := option(reqCtx); err != nil {
+			return nil, err
 		}
 	}
 
-	// execute
-	runner := client.NewRunner(*ctx)
-	if err := runner.DoRequest(); err != nil {
-		return err
-	}
-
-	return nil
+	return client.NewRunner(*reqCtx), nil
+}
 
+func (c Client) endpoint(ep string) string {
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(c.apiUrl, "/"), strings.TrimPrefix(ep, "/"))
 }
@@ -1,66 +1,88 @@
 package client
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
-type AuthentikAuth struct {
-	url string
+// NewAuthentikAuth creates a cached token source for Authentik's client credentials flow
+func NewAuthentikAuth(authUrl, clientID, username, password string) *OAuth2TokenSource {
+	return NewOAuth2TokenSource(OAuth2Config{
+		TokenURL:  fmt.Sprintf("%s/application/o/token/", strings.TrimSuffix(authUrl, "/")),
+		ClientID:  clientID,
+		AuthStyle: OAuth2AuthBody,
+		ExtraParams: url.Values{
+			"grant_type": {"client_credentials"},
+			"username":   {username},
+			"password":   {password},
+		},
+	})
+}
+
+type Authenticator interface {
 
-	clientID string
-	username string
-	password string
+	// Authenticate adds credentials to an outgoing attempt
+	Authenticate(req *http.Request) error
 }
 
-func NewAuthentikAuth(url, clientID, username, password string) *AuthentikAuth {
-	return &AuthentikAuth{
-		url:      url,
-		clientID: clientID,
-		username: username,
-		password: password,
-	}
+// Challenger is implemented by authenticators answering 401 responses; reporting true re-issues the request once
+type Challenger interface {
+	Challenge(req *http.Request, resp *http.Response) (bool, error)
 }
 
-func (a AuthentikAuth) Authenticate() (string, error) {
+type BearerAuth struct {
+	Token string
+}
 
-	// gather data
-	endpoint := fmt.Sprintf("%s/application/o/token/", a.url)
-	payload := url.Values{
-		"grant_type": {"client_credentials"},
-		"client_id":  {a.clientID},
-		"username":   {a.username},
-		"password":   {a.password},
-	}
+func (a BearerAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	return nil
+}
 
-	// send request
-	resp, err := http.PostForm(endpoint, payload)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	// read response
-	if resp.StatusCode != http.StatusOK {
-		errMsg := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-		body, err := io.ReadAll(resp.Body)
-		if err == nil {
-			errMsg = fmt.Errorf("%s: %s", errMsg, string(body))
-		}
-		return "", errMsg
-	}
-	body, err := io.ReadAll(resp.Body)
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (a BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+type HeaderAuth struct {
+	Key   string
+	Value string
+}
+
+func (a HeaderAuth) Authenticate(req *http.Request) error {
+	req.Header.Set(a.Key, a.Value)
+	return nil
+}
+
+// TokenAuth sends bearer tokens of a token source and renews them when rejected
+type TokenAuth struct {
+	Source TokenSource
+}
+
+func (a TokenAuth) Authenticate(req *http.Request) error {
+	token, err := a.Source.Token(req.Context())
 	if err != nil {
-		return "", err
+		return err
 	}
-	var data map[string]interface{}
-	if err := json.Unmarshal(body, &data); err != nil {
-		return "", err
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (a TokenAuth) Challenge(req *http.Request, resp *http.Response) (bool, error) {
+
+	// drop the rejected token only, a concurrent request may have renewed it already
+	if source, ok := a.Source.(ConditionalInvalidator); ok {
+		source.InvalidateIf(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+		return true, nil
 	}
-	accessToken := data["access_token"].(string)
+	a.Source.Invalidate()
 
-	return accessToken, nil
+	return true, nil
 }
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+func makeReplayable(req *http.Request) error {
+
+	// nothing to replay or already replayable
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	// buffer payload
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := req.Body.Close(); err != nil {
+		return err
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.ContentLength = int64(len(data))
+
+	return nil
+}
+
+func cloneRequest(req *http.Request) (*http.Request, error) {
+
+	// copy request
+	clone := req.Clone(req.Context())
+	if req.GetBody == nil {
+		return clone, nil
+	}
+
+	// fresh body
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone.Body = body
+
+	return clone, nil
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReplayableBody(t *testing.T) {
+
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(data))
+		failed := len(bodies) == 1
+		mu.Unlock()
+		if failed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("X-Pages", "2")
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	// payload without GetBody, retried once and paged twice
+	ctx := newTestContext(t, http.MethodPost, server.URL)
+	req, err := http.NewRequest(http.MethodPost, server.URL, io.MultiReader(strings.NewReader(`{"k":"v"}`)))
+	assert.NoError(t, err)
+	ctx.Req = req
+	ctx.RetryPolicy.RetryNonIdempotent = true
+	ctx.Paginator = PageCountPaginator{PageParam: "page", PageCountHeader: "X-Pages"}
+
+	err = NewRunner(ctx).DoRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`{"k":"v"}`, `{"k":"v"}`, `{"k":"v"}`}, bodies)
+
+}
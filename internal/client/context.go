@@ -1,15 +1,16 @@
 package client
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+	"time"
+)
 
 type ResponseProcessor func(*http.Response) error
 
-type PagingConfig struct {
-	ConsumeAll      bool
-	PageParam       string
-	PageCountHeader string
-}
 type RequestContext struct {
+	Context context.Context
+
 	ApiUrl string
 
 	DefaultOptions []RequestOption
@@ -19,14 +20,30 @@ type RequestContext struct {
 
 	Req *http.Request
 
+	Authenticators []Authenticator
+
+	RateLimiters []RateLimiter
+
 	AutoThrottle       bool
-	AutoRetries        int
+	RetryPolicy        RetryPolicy
 	AcceptedErrorCodes []int
 	StatusChecker      StatusChecker
 	Receiver           Receiver
 	ResponseProcessors []ResponseProcessor
-	Paging             PagingConfig
-	SkipTLSVerify      bool
+	Paginator          Paginator
+	PageConcurrency    int
+
+	// Timeout bounds the whole run, AttemptTimeout each single attempt
+	Timeout        time.Duration
+	AttemptTimeout time.Duration
+
+	RedirectPolicy RedirectPolicy
+
+	HTTPClient   *http.Client
+	Jar          http.CookieJar
+	RoundTripper http.RoundTripper
+	Transport    TransportConfig
+	Transports   *TransportPool
 }
 
 type RequestOption func(*RequestContext) error
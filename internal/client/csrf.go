@@ -0,0 +1,52 @@
+package client
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlTagPattern  = regexp.MustCompile(`(?is)<(input|meta)\b[^>]*>`)
+	htmlAttrPattern = regexp.MustCompile(`(?s)([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// ExtractCSRFToken finds a token in the value of a named hidden input, or else the content of a named meta tag
+func ExtractCSRFToken(page []byte, inputName, metaName string) (string, bool) {
+
+	var metaToken string
+	metaFound := false
+	for _, tag := range htmlTagPattern.FindAllSubmatch(page, -1) {
+		attrs := htmlAttributes(string(tag[0]))
+		switch strings.ToLower(string(tag[1])) {
+		case "input":
+			if inputName != "" && attrs["name"] == inputName {
+				return attrs["value"], true
+			}
+		case "meta":
+			if metaName != "" && attrs["name"] == metaName && !metaFound {
+				metaToken, metaFound = attrs["content"], true
+			}
+		}
+	}
+
+	return metaToken, metaFound
+}
+
+// ContainsInput reports if a page holds an input of the given name, e.g. a password field of a login form
+func ContainsInput(page []byte, inputName string) bool {
+	for _, tag := range htmlTagPattern.FindAllSubmatch(page, -1) {
+		if strings.EqualFold(string(tag[1]), "input") && htmlAttributes(string(tag[0]))["name"] == inputName {
+			return true
+		}
+	}
+	return false
+}
+
+func htmlAttributes(tag string) map[string]string {
+	attrs := map[string]string{}
+	for _, match := range htmlAttrPattern.FindAllStringSubmatch(tag, -1) {
+		attrs[strings.ToLower(match[1])] = html.UnescapeString(match[2] + match[3] + match[4])
+	}
+	return attrs
+}
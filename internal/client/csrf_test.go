@@ -0,0 +1,34 @@
+package client
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExtractCSRFToken(t *testing.T) {
+
+	page := []byte(`<html><head>
+		<meta name="csrf-token" content="meta&amp;token">
+	</head><body><form method="post">
+		<INPUT type="hidden" value='input-token' name="authenticity_token" />
+		<input type="password" name="password">
+	</form></body></html>`)
+
+	// hidden input wins
+	token, ok := ExtractCSRFToken(page, "authenticity_token", "csrf-token")
+	assert.True(t, ok)
+	assert.Equal(t, "input-token", token)
+
+	// meta tag, unescaped
+	token, ok = ExtractCSRFToken(page, "_csrf", "csrf-token")
+	assert.True(t, ok)
+	assert.Equal(t, "meta&token", token)
+
+	// missing
+	_, ok = ExtractCSRFToken(page, "_csrf", "")
+	assert.False(t, ok)
+
+	assert.True(t, ContainsInput(page, "password"))
+	assert.False(t, ContainsInput(page, "username"))
+
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DigestAuth answers RFC 7616 challenges and signs following requests with increasing nonce counts
+type DigestAuth struct {
+	Username string
+	Password string
+
+	mu        sync.Mutex
+	challenge *digestChallenge
+	nc        int
+	cnonce    func() string
+}
+
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+}
+
+func NewDigestAuth(username, password string) *DigestAuth {
+	return &DigestAuth{
+		Username: username,
+		Password: password,
+		cnonce:   randomCnonce,
+	}
+}
+
+func (a *DigestAuth) Authenticate(req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// wait for first challenge
+	if a.challenge == nil {
+		return nil
+	}
+
+	// next nonce count
+	a.nc++
+	header, err := a.authorization(req.Method, req.URL.RequestURI(), a.nc, a.cnonce())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", header)
+
+	return nil
+}
+
+func (a *DigestAuth) Challenge(req *http.Request, resp *http.Response) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// pick strongest supported challenge
+	var challenge *digestChallenge
+	stale := false
+	for _, value := range resp.Header.Values("WWW-Authenticate") {
+		scheme, rest, _ := strings.Cut(strings.TrimSpace(value), " ")
+		if !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+		params := parseAuthParams(rest)
+		candidate := &digestChallenge{
+			realm:     params["realm"],
+			nonce:     params["nonce"],
+			opaque:    params["opaque"],
+			algorithm: strings.ToUpper(params["algorithm"]),
+			qop:       params["qop"],
+		}
+		if candidate.algorithm == "" {
+			candidate.algorithm = "MD5"
+		}
+		if digestHash(candidate.algorithm) == nil {
+			continue
+		}
+		if challenge == nil || strings.HasPrefix(candidate.algorithm, "SHA-256") {
+			challenge = candidate
+			stale = strings.EqualFold(params["stale"], "true")
+		}
+	}
+	if challenge == nil {
+		return false, nil
+	}
+
+	// same nonce rejected again means wrong credentials
+	if a.challenge != nil && a.challenge.nonce == challenge.nonce && !stale {
+		return false, nil
+	}
+	a.challenge = challenge
+	a.nc = 0
+
+	return true, nil
+}
+
+func (a *DigestAuth) authorization(method, uri string, nc int, cnonce string) (string, error) {
+
+	c := a.challenge
+	newHash := digestHash(c.algorithm)
+	h := func(s string) string {
+		hasher := newHash()
+		hasher.Write([]byte(s))
+		return hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	// qop
+	qop := ""
+	for _, option := range strings.Split(c.qop, ",") {
+		if strings.TrimSpace(option) == "auth" {
+			qop = "auth"
+		}
+	}
+	if c.qop != "" && qop == "" {
+		return "", fmt.Errorf("unsupported digest qop: %s", c.qop)
+	}
+
+	// response
+	ha1 := h(fmt.Sprintf("%s:%s:%s", a.Username, c.realm, a.Password))
+	if strings.HasSuffix(c.algorithm, "-SESS") {
+		ha1 = h(fmt.Sprintf("%s:%s:%s", ha1, c.nonce, cnonce))
+	}
+	ha2 := h(fmt.Sprintf("%s:%s", method, uri))
+	ncValue := fmt.Sprintf("%08x", nc)
+	response := h(fmt.Sprintf("%s:%s:%s", ha1, c.nonce, ha2))
+	if qop != "" {
+		response = h(fmt.Sprintf("%s:%s:%s:%s:%s:%s", ha1, c.nonce, ncValue, cnonce, qop, ha2))
+	}
+
+	// header
+	parts := []string{
+		fmt.Sprintf(`username="%s"`, a.Username),
+		fmt.Sprintf(`realm="%s"`, c.realm),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf(`algorithm=%s`, c.algorithm),
+		fmt.Sprintf(`nonce="%s"`, c.nonce),
+	}
+	if qop != "" {
+		parts = append(parts, fmt.Sprintf("nc=%s", ncValue), fmt.Sprintf(`cnonce="%s"`, cnonce), fmt.Sprintf("qop=%s", qop))
+	}
+	parts = append(parts, fmt.Sprintf(`response="%s"`, response))
+	if c.opaque != "" {
+		parts = append(parts, fmt.Sprintf(`opaque="%s"`, c.opaque))
+	}
+
+	return "Digest " + strings.Join(parts, ", "), nil
+}
+
+func digestHash(algorithm string) func() hash.Hash {
+	switch strings.TrimSuffix(algorithm, "-SESS") {
+	case "MD5":
+		return md5.New
+	case "SHA-256":
+		return sha256.New
+	}
+	return nil
+}
+
+// parseAuthParams splits comma-separated key=value pairs, honouring quoted values
+func parseAuthParams(s string) map[string]string {
+	params := map[string]string{}
+	for len(s) > 0 {
+
+		// key
+		s = strings.TrimLeft(s, " ,")
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimSpace(rest)
+
+		// quoted value
+		if strings.HasPrefix(rest, `"`) {
+			var value strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				value.WriteByte(rest[i])
+			}
+			params[key] = value.String()
+			s = rest[min(i+1, len(rest)):]
+			continue
+		}
+
+		// token value
+		value, remaining, _ := strings.Cut(rest, ",")
+		params[key] = strings.TrimSpace(value)
+		s = remaining
+	}
+	return params
+}
+
+func randomCnonce() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
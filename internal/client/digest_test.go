@@ -0,0 +1,67 @@
+package client
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// example of RFC 7616 section 3.9.1
+func TestDigestResponse(t *testing.T) {
+
+	for algorithm, expected := range map[string]string{
+		"MD5":     "8ca523f5e9506fed4657c9700eebdbec",
+		"SHA-256": "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1",
+	} {
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Add("WWW-Authenticate", `Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=`+algorithm+`, nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`)
+
+		auth := NewDigestAuth("Mufasa", "Circle of Life")
+		auth.cnonce = func() string { return "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ" }
+		retry, err := auth.Challenge(nil, resp)
+		assert.NoError(t, err)
+		assert.True(t, retry)
+
+		req, err := http.NewRequest(http.MethodGet, "http://www.example.org/dir/index.html", nil)
+		assert.NoError(t, err)
+		assert.NoError(t, auth.Authenticate(req))
+
+		header := req.Header.Get("Authorization")
+		assert.Contains(t, header, `response="`+expected+`"`)
+		assert.Contains(t, header, "nc=00000001")
+		assert.Contains(t, header, `opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`)
+	}
+
+}
+
+func TestDigestRunner(t *testing.T) {
+
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		authorizations = append(authorizations, header)
+		if !strings.HasPrefix(header, "Digest ") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="test", qop="auth", algorithm=SHA-256, nonce="abc"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	auth := NewDigestAuth("user", "pass")
+	for i := 0; i < 2; i++ {
+		ctx := newTestContext(t, http.MethodGet, server.URL+"/dir")
+		ctx.Authenticators = []Authenticator{auth}
+		assert.NoError(t, NewRunner(ctx).DoRequest())
+	}
+
+	// challenged once, then nonce count increases
+	assert.Equal(t, 3, len(authorizations))
+	assert.Equal(t, "", authorizations[0])
+	assert.Contains(t, authorizations[1], "nc=00000001")
+	assert.Contains(t, authorizations[2], "nc=00000002")
+
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrRateLimited      = errors.New("rate limited")
+	ErrRetriesExhausted = errors.New("retries exhausted")
+	ErrBadRequest       = errors.New("bad request")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrForbidden        = errors.New("forbidden")
+	ErrNotFound         = errors.New("not found")
+	ErrConflict         = errors.New("conflict")
+	ErrClientError      = errors.New("client error")
+	ErrServerError      = errors.New("server error")
+	ErrRedirect         = errors.New("redirect")
+	ErrLoginFailed      = errors.New("login failed")
+	ErrSessionExpired   = errors.New("session expired")
+	ErrTimeout          = errors.New("timeout")
+	ErrPinMismatch      = errors.New("certificate pin mismatch")
+)
+
+type HTTPError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Header     http.Header
+	Body       []byte
+
+	// Err optionally classifies the failure beyond its status code, e.g. ErrRetriesExhausted
+	Err error
+}
+
+func NewHTTPError(resp *http.Response, body []byte, kind error) *HTTPError {
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		Err:        kind,
+	}
+	if resp.Request != nil {
+		httpErr.Method = resp.Request.Method
+		httpErr.URL = resp.Request.URL.String()
+	}
+	return httpErr
+}
+
+func (e *HTTPError) Error() string {
+
+	msg := fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	if e.URL != "" {
+		msg = fmt.Sprintf("%s [%s %s]", msg, e.Method, e.URL)
+	}
+	if len(e.Body) > 0 {
+		msg = fmt.Sprintf("%s - %s", msg, string(e.Body))
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%v: %s", e.Err, msg)
+	}
+	return msg
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrClientError:
+		return e.StatusCode >= 400 && e.StatusCode < 500
+	case ErrServerError:
+		return e.StatusCode >= 500 && e.StatusCode < 600
+	case ErrRedirect:
+		return e.StatusCode >= 300 && e.StatusCode < 400
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestHTTPErrorKinds(t *testing.T) {
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/items", nil)
+	assert.NoError(t, err)
+
+	{
+		resp := &http.Response{StatusCode: http.StatusNotFound, Request: req}
+		var httpErr error = NewHTTPError(resp, []byte("missing"), nil)
+		assert.ErrorIs(t, httpErr, ErrNotFound)
+		assert.ErrorIs(t, httpErr, ErrClientError)
+		assert.NotErrorIs(t, httpErr, ErrServerError)
+		assert.Equal(t, "unexpected status code: 404 [GET http://localhost/items] - missing", httpErr.Error())
+	}
+	{
+		resp := &http.Response{StatusCode: http.StatusTooManyRequests, Request: req}
+		wrapped := fmt.Errorf("failed: %w", NewHTTPError(resp, nil, ErrRetriesExhausted))
+		assert.ErrorIs(t, wrapped, ErrRateLimited)
+		assert.ErrorIs(t, wrapped, ErrRetriesExhausted)
+
+		var httpErr *HTTPError
+		assert.True(t, errors.As(wrapped, &httpErr))
+		assert.Equal(t, http.StatusTooManyRequests, httpErr.StatusCode)
+		assert.Equal(t, http.MethodGet, httpErr.Method)
+	}
+
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const DefaultHMACTemplate = "{{.Method}}\n{{.Path}}\n{{.Query}}\n{{.Timestamp}}\n{{.BodyHash}}"
+
+type HMACConfig struct {
+	KeyID  string
+	Secret string
+
+	// headers, signature and timestamp default to X-Signature and X-Timestamp, others are only sent if set
+	SignatureHeader string
+	TimestampHeader string
+	BodyHashHeader  string
+	KeyIDHeader     string
+
+	// Template renders the canonical string from HMACCanonical (defaults to DefaultHMACTemplate)
+	Template string
+}
+
+// HMACCanonical holds the request parts available to the canonicalization template
+type HMACCanonical struct {
+	Method    string
+	Host      string
+	Path      string
+	Query     string
+	Timestamp string
+	BodyHash  string
+	KeyID     string
+}
+
+type HMACSigner struct {
+	cfg      HMACConfig
+	template *template.Template
+	now      func() time.Time
+}
+
+func NewHMACSigner(cfg HMACConfig) (*HMACSigner, error) {
+
+	// defaults
+	if cfg.SignatureHeader == "" {
+		cfg.SignatureHeader = "X-Signature"
+	}
+	if cfg.TimestampHeader == "" {
+		cfg.TimestampHeader = "X-Timestamp"
+	}
+	if cfg.Template == "" {
+		cfg.Template = DefaultHMACTemplate
+	}
+
+	// canonicalization
+	tmpl, err := template.New("hmac").Parse(cfg.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HMACSigner{
+		cfg:      cfg,
+		template: tmpl,
+		now:      time.Now,
+	}, nil
+}
+
+func (s *HMACSigner) Authenticate(req *http.Request) error {
+
+	// gather parts
+	bodyHash, err := hashBody(req)
+	if err != nil {
+		return err
+	}
+	canonical := HMACCanonical{
+		Method:    req.Method,
+		Host:      req.URL.Host,
+		Path:      req.URL.EscapedPath(),
+		Query:     req.URL.Query().Encode(),
+		Timestamp: strconv.FormatInt(s.now().Unix(), 10),
+		BodyHash:  bodyHash,
+		KeyID:     s.cfg.KeyID,
+	}
+	if canonical.Path == "" {
+		canonical.Path = "/"
+	}
+
+	// sign
+	var message strings.Builder
+	if err := s.template.Execute(&message, canonical); err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, []byte(s.cfg.Secret))
+	mac.Write([]byte(message.String()))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	// set headers
+	req.Header.Set(s.cfg.SignatureHeader, signature)
+	req.Header.Set(s.cfg.TimestampHeader, canonical.Timestamp)
+	if s.cfg.BodyHashHeader != "" {
+		req.Header.Set(s.cfg.BodyHashHeader, bodyHash)
+	}
+	if s.cfg.KeyIDHeader != "" {
+		req.Header.Set(s.cfg.KeyIDHeader, s.cfg.KeyID)
+	}
+
+	return nil
+}
+
+// hashBody returns the hex SHA-256 of the request payload without consuming it
+func hashBody(req *http.Request) (string, error) {
+
+	hash := sha256.New()
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		if _, err := io.Copy(hash, body); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACSigning(t *testing.T) {
+
+	signer, err := NewHMACSigner(HMACConfig{
+		KeyID:          "key-1",
+		Secret:         "secret",
+		BodyHashHeader: "X-Content-SHA256",
+		Template:       "{{.KeyID}}|{{.Method}}|{{.Path}}|{{.Query}}|{{.Timestamp}}|{{.BodyHash}}",
+	})
+	assert.NoError(t, err)
+	signer.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.example.com/v1/orders?b=2&a=1", strings.NewReader(`{"k":"v"}`))
+	assert.NoError(t, err)
+	assert.NoError(t, signer.Authenticate(req))
+
+	bodyHash := sha256.Sum256([]byte(`{"k":"v"}`))
+	canonical := "key-1|POST|/v1/orders|a=1&b=2|1700000000|" + hex.EncodeToString(bodyHash[:])
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(canonical))
+
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), req.Header.Get("X-Signature"))
+	assert.Equal(t, "1700000000", req.Header.Get("X-Timestamp"))
+	assert.Equal(t, hex.EncodeToString(bodyHash[:]), req.Header.Get("X-Content-SHA256"))
+
+	// payload is left intact
+	body, err := req.GetBody()
+	assert.NoError(t, err)
+	data := make([]byte, 32)
+	n, _ := body.Read(data)
+	assert.Equal(t, `{"k":"v"}`, string(data[:n]))
+
+	// invalid template
+	_, err = NewHMACSigner(HMACConfig{Template: "{{.Method"})
+	assert.Error(t, err)
+
+}
@@ -9,16 +9,17 @@ import (
 	"strings"
 )
 
+// ParseArrayList concatenates a stream of JSON arrays into a single array, keeping elements as they are
 func ParseArrayList(raw []byte) ([]byte, error) {
 
 	buf := bytes.NewReader(raw)
 	decoder := json.NewDecoder(buf)
 
-	var all []map[string]interface{}
+	all := []json.RawMessage{}
 
 	// Decode JSON arrays one by one
 	for {
-		var page []map[string]interface{}
+		var page []json.RawMessage
 		if err := decoder.Decode(&page); err == io.EOF {
 			break
 		} else if err != nil {
@@ -35,6 +36,58 @@ func ParseArrayList(raw []byte) ([]byte, error) {
 	return merged, nil
 }
 
+// MergeJSONPages concatenates the arrays of all pages, taken from itemsPath of envelope objects if given
+func MergeJSONPages(pages [][]byte, itemsPath string) ([]byte, error) {
+
+	all := []json.RawMessage{}
+	for _, page := range pages {
+
+		// unwrap envelope
+		items, err := pageItems(page, itemsPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// collect elements
+		var list []json.RawMessage
+		if err := json.Unmarshal(items, &list); err != nil {
+			return nil, err
+		}
+		all = append(all, list...)
+	}
+
+	merged, err := json.Marshal(all)
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// lookupJSONPath resolves a dot-separated object path like "meta.next_cursor"
+func lookupJSONPath(raw []byte, path string) (json.RawMessage, bool, error) {
+
+	// whole document
+	value := json.RawMessage(raw)
+	if path == "" {
+		return value, true, nil
+	}
+
+	// descend
+	for _, key := range strings.Split(path, ".") {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(value, &obj); err != nil {
+			return nil, false, fmt.Errorf("cannot resolve %q: %w", path, err)
+		}
+		next, ok := obj[key]
+		if !ok {
+			return nil, false, nil
+		}
+		value = next
+	}
+
+	return value, true, nil
+}
+
 type JsonBool bool
 
 func (sb *JsonBool) UnmarshalJSON(data []byte) error {
@@ -131,3 +131,24 @@ func TestJsonInt64(t *testing.T) {
 		assert.Error(t, err)
 	}
 }
+
+func TestMergeJSONPages(t *testing.T) {
+
+	{
+		pages := [][]byte{[]byte(`["a","b"]`), []byte(`[1, 12345678901234567890]`), []byte(`[]`)}
+		merged, err := MergeJSONPages(pages, "")
+		assert.NoError(t, err)
+		assert.Equal(t, `["a","b",1,12345678901234567890]`, string(merged))
+	}
+	{
+		pages := [][]byte{[]byte(`{"data":{"items":[{"z":1,"a":2}]}}`), []byte(`{"data":{"items":[{"z":3,"a":4}]}}`)}
+		merged, err := MergeJSONPages(pages, "data.items")
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"z":1,"a":2},{"z":3,"a":4}]`, string(merged))
+	}
+	{
+		_, err := MergeJSONPages([][]byte{[]byte(`{"k":"v"}`)}, "")
+		assert.Error(t, err)
+	}
+
+}
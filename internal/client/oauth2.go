@@ -0,0 +1,270 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+	Invalidate()
+}
+
+// ConditionalInvalidator is implemented by token sources able to drop a token only while it is still current
+type ConditionalInvalidator interface {
+	InvalidateIf(token string)
+}
+
+type OAuth2AuthStyle int
+
+const (
+	// OAuth2AuthBasic sends client credentials as basic auth header
+	OAuth2AuthBasic OAuth2AuthStyle = iota
+	// OAuth2AuthBody sends client credentials as form values
+	OAuth2AuthBody
+)
+
+type OAuth2Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	AuthStyle    OAuth2AuthStyle
+
+	// Username and Password switch to the password grant
+	Username string
+	Password string
+
+	Scopes      []string
+	Audience    string
+	ExtraParams url.Values
+
+	// RefreshLeeway renews tokens this long before they expire (defaults to 30s, at most half a token's lifetime)
+	RefreshLeeway time.Duration
+
+	// OnToken is called with every newly obtained token, e.g. to persist it
+	OnToken func(Token)
+
+	HTTPClient *http.Client
+}
+
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+func (t Token) valid(now, renewAt time.Time) bool {
+	if t.AccessToken == "" {
+		return false
+	}
+	if renewAt.IsZero() {
+		return true
+	}
+	return now.Before(renewAt)
+}
+
+type OAuth2TokenSource struct {
+	cfg OAuth2Config
+	now func() time.Time
+
+	mu      sync.Mutex
+	token   *Token
+	renewAt time.Time
+}
+
+func NewOAuth2TokenSource(cfg OAuth2Config) *OAuth2TokenSource {
+	if cfg.RefreshLeeway == 0 {
+		cfg.RefreshLeeway = 30 * time.Second
+	}
+	return &OAuth2TokenSource{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+func (ts *OAuth2TokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	// cached
+	if ts.token != nil && ts.token.valid(ts.now(), ts.renewAt) {
+		return ts.token.AccessToken, nil
+	}
+
+	// renew via refresh token, or fetch new
+	token, err := ts.renew(ctx)
+	if err != nil {
+		return "", err
+	}
+	ts.keep(token)
+	if ts.cfg.OnToken != nil {
+		ts.cfg.OnToken(*token)
+	}
+
+	return token.AccessToken, nil
+}
+
+// Invalidate drops the access token, keeping a refresh token for renewal
+func (ts *OAuth2TokenSource) Invalidate() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.token != nil {
+		ts.token.AccessToken = ""
+	}
+}
+
+// InvalidateIf drops the access token only if it is still token, sparing one renewed meanwhile
+func (ts *OAuth2TokenSource) InvalidateIf(token string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.token != nil && ts.token.AccessToken == token {
+		ts.token.AccessToken = ""
+	}
+}
+
+// SetToken restores a previously obtained token, e.g. after a restart
+func (ts *OAuth2TokenSource) SetToken(token Token) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.keep(&token)
+}
+
+// keep caches token, renewing it ahead of expiry by the leeway but at most by half its remaining lifetime
+func (ts *OAuth2TokenSource) keep(token *Token) {
+	ts.token = token
+	ts.renewAt = time.Time{}
+	if !token.Expiry.IsZero() {
+		leeway := min(ts.cfg.RefreshLeeway, token.Expiry.Sub(ts.now())/2)
+		ts.renewAt = token.Expiry.Add(-leeway)
+	}
+}
+
+func (ts *OAuth2TokenSource) renew(ctx context.Context) (*Token, error) {
+
+	// full authentication without refresh token
+	if ts.token == nil || ts.token.RefreshToken == "" {
+		return ts.fetch(ctx)
+	}
+
+	// refresh grant
+	payload := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {ts.token.RefreshToken},
+	}
+	if len(ts.cfg.Scopes) > 0 {
+		payload.Set("scope", strings.Join(ts.cfg.Scopes, " "))
+	}
+	token, err := ts.requestToken(ctx, payload)
+
+	// re-authenticate if rejected
+	if errors.Is(err, ErrBadRequest) || errors.Is(err, ErrUnauthorized) {
+		return ts.fetch(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// keep refresh token unless rotated
+	if token.RefreshToken == "" {
+		token.RefreshToken = ts.token.RefreshToken
+	}
+
+	return token, nil
+}
+
+func (ts *OAuth2TokenSource) fetch(ctx context.Context) (*Token, error) {
+
+	// gather data
+	payload := url.Values{}
+	for key, values := range ts.cfg.ExtraParams {
+		payload[key] = values
+	}
+	if ts.cfg.Username != "" {
+		payload.Set("grant_type", "password")
+		payload.Set("username", ts.cfg.Username)
+		payload.Set("password", ts.cfg.Password)
+	} else if payload.Get("grant_type") == "" {
+		payload.Set("grant_type", "client_credentials")
+	}
+	if len(ts.cfg.Scopes) > 0 {
+		payload.Set("scope", strings.Join(ts.cfg.Scopes, " "))
+	}
+	if ts.cfg.Audience != "" {
+		payload.Set("audience", ts.cfg.Audience)
+	}
+
+	return ts.requestToken(ctx, payload)
+}
+
+func (ts *OAuth2TokenSource) requestToken(ctx context.Context, payload url.Values) (*Token, error) {
+
+	// client credentials
+	if ts.cfg.AuthStyle == OAuth2AuthBody {
+		payload.Set("client_id", ts.cfg.ClientID)
+		if ts.cfg.ClientSecret != "" {
+			payload.Set("client_secret", ts.cfg.ClientSecret)
+		}
+	}
+
+	// send request
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.cfg.TokenURL, strings.NewReader(payload.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if ts.cfg.AuthStyle == OAuth2AuthBasic {
+		req.SetBasicAuth(url.QueryEscape(ts.cfg.ClientID), url.QueryEscape(ts.cfg.ClientSecret))
+	}
+	client := ts.cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewHTTPError(resp, body, nil)
+	}
+	var data struct {
+		AccessToken  string    `json:"access_token"`
+		TokenType    string    `json:"token_type"`
+		RefreshToken string    `json:"refresh_token"`
+		ExpiresIn    JsonInt64 `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	if data.AccessToken == "" {
+		return nil, fmt.Errorf("token response from [%s] contains no access token", ts.cfg.TokenURL)
+	}
+
+	// pack
+	token := &Token{
+		AccessToken:  data.AccessToken,
+		TokenType:    data.TokenType,
+		RefreshToken: data.RefreshToken,
+	}
+	if data.ExpiresIn > 0 {
+		token.Expiry = ts.now().Add(time.Duration(data.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
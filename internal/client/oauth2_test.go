@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOAuth2TokenSource(t *testing.T) {
+
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "client" || pass != "secret" || r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "read write" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		n := issued.Add(1)
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":"3600"}`, n)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	ts := NewOAuth2TokenSource(OAuth2Config{
+		TokenURL:     server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	})
+	ts.now = func() time.Time { return now }
+
+	// concurrent callers share one token
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := ts.Token(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "token-1", token)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), issued.Load())
+
+	// refreshed shortly before expiry
+	now = now.Add(3590 * time.Second)
+	token, err := ts.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+	// refetched after invalidation
+	ts.Invalidate()
+	token, err = ts.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-3", token)
+
+}
+
+func TestTokenAuthRetriesUnauthorized(t *testing.T) {
+
+	var issued atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d"}`, issued.Add(1))
+	}))
+	defer tokenServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer apiServer.Close()
+
+	ctx := newTestContext(t, http.MethodGet, apiServer.URL)
+	ctx.Authenticators = []Authenticator{TokenAuth{Source: NewOAuth2TokenSource(OAuth2Config{TokenURL: tokenServer.URL, ClientID: "client"})}}
+
+	err := NewRunner(ctx).DoRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), issued.Load())
+
+}
+
+func TestTokenAuthKeepsRenewedToken(t *testing.T) {
+
+	var issued atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d"}`, issued.Add(1))
+	}))
+	defer tokenServer.Close()
+
+	source := NewOAuth2TokenSource(OAuth2Config{TokenURL: tokenServer.URL, ClientID: "client"})
+	auth := TokenAuth{Source: source}
+	token, err := source.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// late rejection of an older token
+	stale, err := http.NewRequest(http.MethodGet, "http://api.example", nil)
+	assert.NoError(t, err)
+	stale.Header.Set("Authorization", "Bearer token-0")
+	retry, err := auth.Challenge(stale, &http.Response{StatusCode: http.StatusUnauthorized})
+	assert.NoError(t, err)
+	assert.True(t, retry)
+	token, err = source.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, int32(1), issued.Load())
+
+	// rejection of the current token
+	current := stale.Clone(stale.Context())
+	current.Header.Set("Authorization", "Bearer token-1")
+	_, err = auth.Challenge(current, &http.Response{StatusCode: http.StatusUnauthorized})
+	assert.NoError(t, err)
+	token, err = source.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+}
+
+func TestOAuth2ShortLivedToken(t *testing.T) {
+
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":30}`, issued.Add(1))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	ts := NewOAuth2TokenSource(OAuth2Config{TokenURL: server.URL, ClientID: "client"})
+	ts.now = func() time.Time { return now }
+
+	// cached despite lifetime within the leeway
+	for i := 0; i < 5; i++ {
+		token, err := ts.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "token-1", token)
+	}
+	assert.Equal(t, int32(1), issued.Load())
+
+	// renewed after half its lifetime
+	now = now.Add(16 * time.Second)
+	token, err := ts.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+}
+
+func TestOAuth2RefreshToken(t *testing.T) {
+
+	var grants []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		grant := r.FormValue("grant_type")
+		grants = append(grants, grant)
+		switch {
+		case grant == "password":
+			_, _ = w.Write([]byte(`{"access_token":"access-1","refresh_token":"refresh-1","expires_in":60}`))
+		case grant == "refresh_token" && r.FormValue("refresh_token") == "refresh-1":
+			_, _ = w.Write([]byte(`{"access_token":"access-2","expires_in":60}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+		}
+	}))
+	defer server.Close()
+
+	var persisted []Token
+	now := time.Now()
+	ts := NewOAuth2TokenSource(OAuth2Config{
+		TokenURL: server.URL,
+		ClientID: "client",
+		Username: "user",
+		Password: "pass",
+		OnToken:  func(token Token) { persisted = append(persisted, token) },
+	})
+	ts.now = func() time.Time { return now }
+
+	// initial login
+	token, err := ts.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "access-1", token)
+
+	// renewed via refresh token, which is kept
+	now = now.Add(time.Minute)
+	token, err = ts.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "access-2", token)
+	assert.Equal(t, "refresh-1", persisted[1].RefreshToken)
+
+	// rejected refresh token falls back to login
+	ts.SetToken(Token{AccessToken: "stale", RefreshToken: "revoked", Expiry: now.Add(-time.Minute)})
+	token, err = ts.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "access-1", token)
+
+	assert.Equal(t, []string{"password", "refresh_token", "refresh_token", "password"}, grants)
+	assert.Equal(t, 3, len(persisted))
+
+}
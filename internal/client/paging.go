@@ -0,0 +1,380 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Paginator interface {
+
+	// First prepares the request for the first page
+	First(req *http.Request) (*http.Request, error)
+
+	// Next returns the request for the page following resp, or nil when done
+	Next(req *http.Request, resp *http.Response, body []byte) (*http.Request, error)
+
+	// Items extracts the items of a page body
+	Items(body []byte) ([]byte, error)
+}
+
+// PageRanger is implemented by paginators that know all remaining pages once the first one arrived,
+// which allows fetching them concurrently
+type PageRanger interface {
+	Remaining(req *http.Request, resp *http.Response, body []byte) ([]*http.Request, bool, error)
+}
+
+// singlePaginator treats the response as the only page
+type singlePaginator struct{}
+
+func (p singlePaginator) First(req *http.Request) (*http.Request, error) {
+	return req, nil
+}
+
+func (p singlePaginator) Next(req *http.Request, resp *http.Response, body []byte) (*http.Request, error) {
+	return nil, nil
+}
+
+func (p singlePaginator) Items(body []byte) ([]byte, error) {
+	return body, nil
+}
+
+// PageCountPaginator counts 1-based pages up to the total announced in a header
+type PageCountPaginator struct {
+	PageParam       string
+	PageCountHeader string
+}
+
+func (p PageCountPaginator) First(req *http.Request) (*http.Request, error) {
+	return withQueryParam(req, p.PageParam, "1"), nil
+}
+
+func (p PageCountPaginator) Next(req *http.Request, resp *http.Response, body []byte) (*http.Request, error) {
+
+	// total pages
+	exp := resp.Header.Get(p.PageCountHeader)
+	if exp == "" {
+		return nil, nil
+	}
+	totalPages, err := strconv.Atoi(strings.TrimSpace(exp))
+	if err != nil {
+		return nil, fmt.Errorf("invalid page count header %s: %w", p.PageCountHeader, err)
+	}
+
+	// current page
+	page, err := strconv.Atoi(req.URL.Query().Get(p.PageParam))
+	if err != nil {
+		return nil, err
+	}
+	if page >= totalPages {
+		return nil, nil
+	}
+
+	return withQueryParam(req, p.PageParam, strconv.Itoa(page+1)), nil
+}
+
+func (p PageCountPaginator) Items(body []byte) ([]byte, error) {
+	return body, nil
+}
+
+func (p PageCountPaginator) Remaining(req *http.Request, resp *http.Response, body []byte) ([]*http.Request, bool, error) {
+
+	// total pages
+	exp := resp.Header.Get(p.PageCountHeader)
+	if exp == "" {
+		return nil, true, nil
+	}
+	totalPages, err := strconv.Atoi(strings.TrimSpace(exp))
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid page count header %s: %w", p.PageCountHeader, err)
+	}
+
+	// following pages
+	page, err := strconv.Atoi(req.URL.Query().Get(p.PageParam))
+	if err != nil {
+		return nil, false, err
+	}
+	var remaining []*http.Request
+	for next := page + 1; next <= totalPages; next++ {
+		remaining = append(remaining, withQueryParam(req, p.PageParam, strconv.Itoa(next)))
+	}
+
+	return remaining, true, nil
+}
+
+// LinkPaginator follows RFC 8288 rel="next" links
+type LinkPaginator struct{}
+
+func (p LinkPaginator) First(req *http.Request) (*http.Request, error) {
+	return req, nil
+}
+
+func (p LinkPaginator) Next(req *http.Request, resp *http.Response, body []byte) (*http.Request, error) {
+
+	// next target
+	next, ok := nextLink(resp.Header)
+	if !ok {
+		return nil, nil
+	}
+	nextURL, err := req.URL.Parse(next)
+	if err != nil {
+		return nil, err
+	}
+
+	// point request at it
+	nextReq := req.Clone(req.Context())
+	nextReq.URL = nextURL
+	nextReq.Host = ""
+
+	return nextReq, nil
+}
+
+func (p LinkPaginator) Items(body []byte) ([]byte, error) {
+	return body, nil
+}
+
+// CursorPaginator passes a cursor taken from the response body to the next request
+type CursorPaginator struct {
+	CursorPath  string
+	CursorParam string
+	ItemsPath   string
+}
+
+func (p CursorPaginator) First(req *http.Request) (*http.Request, error) {
+	return req, nil
+}
+
+func (p CursorPaginator) Next(req *http.Request, resp *http.Response, body []byte) (*http.Request, error) {
+	cursor, err := pageCursor(body, p.CursorPath)
+	if err != nil {
+		return nil, err
+	}
+	if cursor == "" || cursor == req.URL.Query().Get(p.CursorParam) {
+		return nil, nil
+	}
+	return withQueryParam(req, p.CursorParam, cursor), nil
+}
+
+func (p CursorPaginator) Items(body []byte) ([]byte, error) {
+	return pageItems(body, p.ItemsPath)
+}
+
+// OffsetPaginator advances an item offset by the page size until a short page or the total is reached
+type OffsetPaginator struct {
+	OffsetParam string
+	LimitParam  string
+	PageSize    int
+	TotalHeader string
+	TotalPath   string
+	ItemsPath   string
+}
+
+func (p OffsetPaginator) First(req *http.Request) (*http.Request, error) {
+	return p.at(req, 0), nil
+}
+
+func (p OffsetPaginator) Next(req *http.Request, resp *http.Response, body []byte) (*http.Request, error) {
+
+	// stop at short or empty page
+	items, err := p.Items(body)
+	if err != nil {
+		return nil, err
+	}
+	count, err := countItems(items)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 || count < p.PageSize {
+		return nil, nil
+	}
+
+	// stop at total
+	offset, err := strconv.Atoi(req.URL.Query().Get(p.OffsetParam))
+	if err != nil {
+		return nil, err
+	}
+	offset += p.PageSize
+	total, ok, err := pageTotal(resp, body, p.TotalHeader, p.TotalPath)
+	if err != nil {
+		return nil, err
+	}
+	if ok && offset >= total {
+		return nil, nil
+	}
+
+	return p.at(req, offset), nil
+}
+
+func (p OffsetPaginator) Items(body []byte) ([]byte, error) {
+	return pageItems(body, p.ItemsPath)
+}
+
+func (p OffsetPaginator) Remaining(req *http.Request, resp *http.Response, body []byte) ([]*http.Request, bool, error) {
+
+	// nothing more after short or empty page
+	items, err := p.Items(body)
+	if err != nil {
+		return nil, false, err
+	}
+	count, err := countItems(items)
+	if err != nil {
+		return nil, false, err
+	}
+	if count == 0 || count < p.PageSize {
+		return nil, true, nil
+	}
+
+	// total must be known
+	total, ok, err := pageTotal(resp, body, p.TotalHeader, p.TotalPath)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	// following offsets
+	offset, err := strconv.Atoi(req.URL.Query().Get(p.OffsetParam))
+	if err != nil {
+		return nil, false, err
+	}
+	var remaining []*http.Request
+	for next := offset + p.PageSize; next < total; next += p.PageSize {
+		remaining = append(remaining, p.at(req, next))
+	}
+
+	return remaining, true, nil
+}
+
+func (p OffsetPaginator) at(req *http.Request, offset int) *http.Request {
+	next := withQueryParam(req, p.OffsetParam, strconv.Itoa(offset))
+	if p.LimitParam != "" {
+		next = withQueryParam(next, p.LimitParam, strconv.Itoa(p.PageSize))
+	}
+	return next
+}
+
+// withQueryParam copies the request with a query parameter set
+func withQueryParam(req *http.Request, key, value string) *http.Request {
+	next := req.Clone(req.Context())
+	values := next.URL.Query()
+	values.Set(key, value)
+	next.URL.RawQuery = values.Encode()
+	return next
+}
+
+// nextLink finds the rel="next" target of RFC 8288 Link headers
+func nextLink(header http.Header) (string, bool) {
+	for _, value := range header.Values("Link") {
+		for {
+
+			// find target
+			start := strings.Index(value, "<")
+			end := strings.Index(value, ">")
+			if start < 0 || end < start {
+				break
+			}
+			target := value[start+1 : end]
+			value = value[end+1:]
+
+			// params run until the next link
+			params := value
+			if next := strings.Index(value, "<"); next >= 0 {
+				params = value[:next]
+			}
+			for _, param := range strings.Split(params, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `",`)) {
+					if strings.EqualFold(rel, "next") {
+						return target, true
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// pageItems extracts the items array of a page body
+func pageItems(body []byte, itemsPath string) ([]byte, error) {
+	if itemsPath == "" {
+		return body, nil
+	}
+	items, ok, err := lookupJSONPath(body, itemsPath)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || string(items) == "null" {
+		return []byte("[]"), nil
+	}
+	return items, nil
+}
+
+// pageCursor extracts the next cursor of a page body, empty if there is none
+func pageCursor(body []byte, cursorPath string) (string, error) {
+
+	raw, ok, err := lookupJSONPath(body, cursorPath)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	// string cursor
+	var cursor string
+	if err := json.Unmarshal(raw, &cursor); err == nil {
+		return cursor, nil
+	}
+
+	// numeric cursor
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number.String(), nil
+	}
+
+	// no cursor
+	if string(raw) == "null" || string(raw) == "false" {
+		return "", nil
+	}
+
+	return "", fmt.Errorf("invalid cursor at %q: %s", cursorPath, string(raw))
+}
+
+// countItems counts the elements of a JSON array
+func countItems(items []byte) (int, error) {
+	var list []json.RawMessage
+	if err := json.Unmarshal(items, &list); err != nil {
+		return 0, err
+	}
+	return len(list), nil
+}
+
+// pageTotal reads the total item count from a header or body path, if available
+func pageTotal(res *http.Response, body []byte, totalHeader, totalPath string) (int, bool, error) {
+
+	// header
+	if totalHeader != "" {
+		if exp := res.Header.Get(totalHeader); exp != "" {
+			total, err := strconv.Atoi(strings.TrimSpace(exp))
+			if err != nil {
+				return 0, false, fmt.Errorf("invalid total count header %s: %w", totalHeader, err)
+			}
+			return total, true, nil
+		}
+	}
+
+	// body
+	if totalPath != "" {
+		raw, ok, err := lookupJSONPath(body, totalPath)
+		if err != nil || !ok {
+			return 0, false, err
+		}
+		var total JsonInt64
+		if err := json.Unmarshal(raw, &total); err != nil {
+			return 0, false, fmt.Errorf("invalid total count at %q: %w", totalPath, err)
+		}
+		return int(total), true, nil
+	}
+
+	return 0, false, nil
+}
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNextLink(t *testing.T) {
+
+	{
+		header := http.Header{}
+		header.Add("Link", `<https://api.example.com/items?page=1>; rel="prev", <https://api.example.com/items?page=3>; rel="next"`)
+		next, ok := nextLink(header)
+		assert.True(t, ok)
+		assert.Equal(t, "https://api.example.com/items?page=3", next)
+	}
+	{
+		header := http.Header{}
+		header.Add("Link", `</items?page=1>; rel="first"`)
+		header.Add("Link", `</items?page=2>; rel="next last"`)
+		next, ok := nextLink(header)
+		assert.True(t, ok)
+		assert.Equal(t, "/items?page=2", next)
+	}
+	{
+		header := http.Header{}
+		header.Add("Link", `</items?page=1>; rel="prev"`)
+		_, ok := nextLink(header)
+		assert.False(t, ok)
+	}
+
+}
+
+func TestLinkPaging(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		if page != "3" {
+			next := map[string]string{"1": "2", "2": "3"}[page]
+			w.Header().Set("Link", fmt.Sprintf(`</items?page=%s>; rel="next"`, next))
+		}
+		_, _ = fmt.Fprintf(w, `[{"page":%s}]`, page)
+	}))
+	defer server.Close()
+
+	var pages [][]byte
+	ctx := newTestContext(t, http.MethodGet, server.URL+"/items")
+	ctx.Paginator = LinkPaginator{}
+	ctx.Receiver = func(payload [][]byte) error {
+		pages = payload
+		return nil
+	}
+
+	err := NewRunner(ctx).DoRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(`[{"page":1}]`), []byte(`[{"page":2}]`), []byte(`[{"page":3}]`)}, pages)
+
+}
+
+func TestCursorPaging(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			_, _ = w.Write([]byte(`{"data":{"items":[{"k":"v1"},{"k":"v2"}]},"meta":{"next_cursor":"abc"}}`))
+		case "abc":
+			_, _ = w.Write([]byte(`{"data":{"items":[{"k":"v3"}]},"meta":{"next_cursor":null}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	var pages [][]byte
+	ctx := newTestContext(t, http.MethodGet, server.URL+"/items")
+	ctx.Paginator = CursorPaginator{CursorPath: "meta.next_cursor", CursorParam: "cursor", ItemsPath: "data.items"}
+	ctx.Receiver = func(payload [][]byte) error {
+		pages = payload
+		return nil
+	}
+
+	err := NewRunner(ctx).DoRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(`[{"k":"v1"},{"k":"v2"}]`), []byte(`[{"k":"v3"}]`)}, pages)
+
+}
+
+func TestOffsetPaging(t *testing.T) {
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-Total-Count", "4")
+		switch r.URL.Query().Get("offset") {
+		case "0":
+			_, _ = w.Write([]byte(`[1,2]`))
+		case "2":
+			_, _ = w.Write([]byte(`[3,4]`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	// stop at total
+	{
+		var pages [][]byte
+		requests = 0
+		ctx := newTestContext(t, http.MethodGet, server.URL+"/items")
+		ctx.Paginator = OffsetPaginator{OffsetParam: "offset", LimitParam: "limit", PageSize: 2, TotalHeader: "X-Total-Count"}
+		ctx.Receiver = func(payload [][]byte) error {
+			pages = payload
+			return nil
+		}
+		err := NewRunner(ctx).DoRequest()
+		assert.NoError(t, err)
+		assert.Equal(t, [][]byte{[]byte(`[1,2]`), []byte(`[3,4]`)}, pages)
+		assert.Equal(t, 2, requests)
+	}
+
+	// stop at empty page
+	{
+		var pages [][]byte
+		requests = 0
+		ctx := newTestContext(t, http.MethodGet, server.URL+"/items")
+		ctx.Paginator = OffsetPaginator{OffsetParam: "offset", LimitParam: "limit", PageSize: 2}
+		ctx.Receiver = func(payload [][]byte) error {
+			pages = payload
+			return nil
+		}
+		err := NewRunner(ctx).DoRequest()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(pages))
+		assert.Equal(t, 3, requests)
+	}
+
+}
+
+func TestStreamingPages(t *testing.T) {
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-Pages", "5")
+		_, _ = fmt.Fprintf(w, `[%s]`, r.URL.Query().Get("page"))
+	}))
+	defer server.Close()
+
+	ctx := newTestContext(t, http.MethodGet, server.URL+"/items")
+	ctx.Paginator = PageCountPaginator{PageParam: "page", PageCountHeader: "X-Pages"}
+
+	// stop fetching once the loop breaks
+	var pages []string
+	for page, err := range NewRunner(ctx).Pages() {
+		assert.NoError(t, err)
+		pages = append(pages, string(page))
+		if len(pages) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"[1]", "[2]"}, pages)
+	assert.Equal(t, 2, requests)
+
+}
+
+func TestConcurrentPaging(t *testing.T) {
+
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		w.Header().Set("X-Pages", "12")
+		_, _ = fmt.Fprintf(w, `[%s]`, r.URL.Query().Get("page"))
+	}))
+	defer server.Close()
+
+	var pages []string
+	ctx := newTestContext(t, http.MethodGet, server.URL+"/items")
+	ctx.Paginator = PageCountPaginator{PageParam: "page", PageCountHeader: "X-Pages"}
+	ctx.PageConcurrency = 3
+	ctx.Receiver = func(payload [][]byte) error {
+		for _, page := range payload {
+			pages = append(pages, string(page))
+		}
+		return nil
+	}
+
+	err := NewRunner(ctx).DoRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"[1]", "[2]", "[3]", "[4]", "[5]", "[6]", "[7]", "[8]", "[9]", "[10]", "[11]", "[12]"}, pages)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+)
+
+type RateLimiter interface {
+
+	// Wait blocks until the attempt may be sent, or its context ends
+	Wait(req *http.Request) error
+}
+
+// TokenBucket allows bursts of up to burst requests, refilled at perSecond; it is safe for concurrent use
+type TokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(perSecond float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *TokenBucket) Wait(req *http.Request) error {
+	return b.WaitCtx(req.Context())
+}
+
+func (b *TokenBucket) WaitCtx(ctx context.Context) error {
+
+	// reserve
+	delay := b.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	// wait for the token, handing it back if cancelled
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.release()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token, possibly ahead of time, and returns how long until it is available
+func (b *TokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// refill
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	// take
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *TokenBucket) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+1)
+}
+
+// HostRateLimiter keeps a separate bucket for each host
+type HostRateLimiter struct {
+	rate  float64
+	burst int
+
+	mu      sync.Mutex
+	buckets map[string]*TokenBucket
+}
+
+func NewHostRateLimiter(perSecond float64, burst int) *HostRateLimiter {
+	return &HostRateLimiter{
+		rate:    perSecond,
+		burst:   burst,
+		buckets: map[string]*TokenBucket{},
+	}
+}
+
+func (l *HostRateLimiter) Wait(req *http.Request) error {
+	return l.bucket(req.URL.Host).Wait(req)
+}
+
+func (l *HostRateLimiter) bucket(host string) *TokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bucket, ok := l.buckets[host]
+	if !ok {
+		bucket = NewTokenBucket(l.rate, l.burst)
+		l.buckets[host] = bucket
+	}
+	return bucket
+}
+
+// EndpointRateLimiter limits requests whose path matches Pattern, see path.Match
+type EndpointRateLimiter struct {
+	Pattern string
+	Bucket  *TokenBucket
+}
+
+func (l EndpointRateLimiter) Wait(req *http.Request) error {
+	if matched, _ := path.Match(l.Pattern, req.URL.Path); !matched {
+		return nil
+	}
+	return l.Bucket.Wait(req)
+}
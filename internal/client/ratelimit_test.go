@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+
+	// burst passes, the rest is paced
+	bucket := NewTokenBucket(200, 10)
+	started := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, bucket.WaitCtx(context.Background()))
+		}()
+	}
+	wg.Wait()
+	assert.GreaterOrEqual(t, time.Since(started), 190*time.Millisecond)
+
+	// cancelled waits hand back their token
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	slow := NewTokenBucket(1, 1)
+	assert.NoError(t, slow.WaitCtx(ctx))
+	assert.ErrorIs(t, slow.WaitCtx(ctx), context.DeadlineExceeded)
+	assert.InDelta(t, 0, slow.tokens, 0.1)
+
+}
+
+func TestRateLimiters(t *testing.T) {
+
+	request := func(url string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.NoError(t, err)
+		return req
+	}
+	exhausted := func(limiter RateLimiter, url string) bool {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		return limiter.Wait(request(url).WithContext(ctx)) != nil
+	}
+
+	// separate bucket per host
+	hosts := NewHostRateLimiter(1, 1)
+	assert.False(t, exhausted(hosts, "http://a.example/"))
+	assert.True(t, exhausted(hosts, "http://a.example/"))
+	assert.False(t, exhausted(hosts, "http://b.example/"))
+
+	// matching endpoints only
+	endpoints := EndpointRateLimiter{Pattern: "/api/search/*", Bucket: NewTokenBucket(1, 1)}
+	assert.False(t, exhausted(endpoints, "http://a.example/api/search/users"))
+	assert.True(t, exhausted(endpoints, "http://a.example/api/search/groups"))
+	assert.False(t, exhausted(endpoints, "http://a.example/api/users"))
+
+}
+
+func TestRateLimitedRetries(t *testing.T) {
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// retries draw from the bucket
+	ctx := newTestContext(t, http.MethodGet, server.URL)
+	ctx.RateLimiters = []RateLimiter{NewTokenBucket(20, 1)}
+	started := time.Now()
+	assert.NoError(t, NewRunner(ctx).DoRequest())
+	assert.Equal(t, int32(3), calls.Load())
+	assert.GreaterOrEqual(t, time.Since(started), 90*time.Millisecond)
+
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"slices"
+)
+
+type credentialHeadersKey struct{}
+
+// RedirectPolicy controls following 3xx responses; the zero value follows none, surfacing them as errors
+type RedirectPolicy struct {
+
+	// MaxRedirects limits the redirects followed per attempt
+	MaxRedirects int
+
+	// SameHostOnly stops at redirects leaving the original host
+	SameHostOnly bool
+}
+
+// CheckRedirect implements http.Client.CheckRedirect; 307 and 308 keep method and body, as payloads are replayable,
+// and credentials set by authenticators or cookies are not sent to other hosts
+func (p RedirectPolicy) CheckRedirect(req *http.Request, via []*http.Request) error {
+
+	// limit hops
+	if len(via) > p.MaxRedirects {
+		return http.ErrUseLastResponse
+	}
+
+	// guard credentials
+	if req.URL.Host != via[0].URL.Host {
+		if p.SameHostOnly {
+			return http.ErrUseLastResponse
+		}
+		req.Header.Del("Authorization")
+		req.Header.Del("Cookie")
+		names, _ := req.Context().Value(credentialHeadersKey{}).([]string)
+		for _, name := range names {
+			req.Header.Del(name)
+		}
+	}
+
+	return nil
+}
+
+// markCredentials notes the headers authenticators set on req, so redirects leaving the host can drop them
+func markCredentials(req *http.Request, unauthenticated http.Header) *http.Request {
+	var names []string
+	for name, values := range req.Header {
+		if !slices.Equal(unauthenticated[name], values) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), credentialHeadersKey{}, names))
+}
+
+// RedirectChain returns the redirect responses that led to resp, oldest first; their bodies are closed
+func RedirectChain(resp *http.Response) []*http.Response {
+	var chain []*http.Response
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		chain = append([]*http.Response{req.Response}, chain...)
+	}
+	return chain
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedirectPolicy(t *testing.T) {
+
+	// other host, reporting leaked credentials
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Header.Get("Authorization")+r.Header.Get("X-Api-Key")+r.Header.Get("Cookie"))
+	}))
+	defer other.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/hop", http.StatusFound)
+		case "/hop":
+			http.Redirect(w, r, "/final", http.StatusMovedPermanently)
+		case "/away":
+			http.Redirect(w, r, other.URL, http.StatusFound)
+		case "/temporary":
+			http.Redirect(w, r, "/final", http.StatusTemporaryRedirect)
+		case "/final":
+			body, _ := io.ReadAll(r.Body)
+			_, _ = io.WriteString(w, r.Method+" "+string(body)+" "+r.Header.Get("Authorization")+" "+r.Header.Get("X-Api-Key"))
+		}
+	}))
+	defer server.Close()
+
+	run := func(method, path string, policy RedirectPolicy, body string) (string, []*http.Response, error) {
+		ctx := newTestContext(t, method, server.URL+path)
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("Cookie", "session=secret")
+		ctx.Req = req
+		ctx.Authenticators = []Authenticator{HeaderAuth{Key: "X-Api-Key", Value: "topsecret"}}
+		ctx.RedirectPolicy = policy
+		var received string
+		var chain []*http.Response
+		ctx.Receiver = func(payload [][]byte) error {
+			received = string(MergePages(payload))
+			return nil
+		}
+		ctx.ResponseProcessors = []ResponseProcessor{func(resp *http.Response) error {
+			chain = RedirectChain(resp)
+			return nil
+		}}
+		return received, chain, NewRunner(ctx).DoRequest()
+	}
+
+	// not followed by default
+	_, _, err := run(http.MethodGet, "/moved", RedirectPolicy{}, "")
+	assert.True(t, errors.Is(err, ErrRedirect))
+
+	// followed, with chain
+	received, chain, err := run(http.MethodGet, "/moved", RedirectPolicy{MaxRedirects: 5}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "GET  Bearer secret topsecret", received)
+	if assert.Len(t, chain, 2) {
+		assert.Equal(t, http.StatusFound, chain[0].StatusCode)
+		assert.Equal(t, "/hop", chain[0].Header.Get("Location"))
+		assert.Equal(t, http.StatusMovedPermanently, chain[1].StatusCode)
+	}
+
+	// limited
+	_, _, err = run(http.MethodGet, "/moved", RedirectPolicy{MaxRedirects: 1}, "")
+	assert.True(t, errors.Is(err, ErrRedirect))
+
+	// method and body kept on 307
+	received, _, err = run(http.MethodPost, "/temporary", RedirectPolicy{MaxRedirects: 1}, "payload")
+	assert.NoError(t, err)
+	assert.Equal(t, "POST payload Bearer secret topsecret", received)
+
+	// credentials, header auth and cookies stripped across hosts
+	received, _, err = run(http.MethodGet, "/away", RedirectPolicy{MaxRedirects: 1}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "", received)
+
+	// same host only
+	_, _, err = run(http.MethodGet, "/away", RedirectPolicy{MaxRedirects: 1, SameHostOnly: true}, "")
+	assert.True(t, errors.Is(err, ErrRedirect))
+
+}
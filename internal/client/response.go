@@ -2,7 +2,6 @@ package client
 
 import (
 	"bytes"
-	"fmt"
 	"net/http"
 )
 
@@ -12,31 +11,43 @@ type responseProcessor struct {
 
 func (rp responseProcessor) process(resp *http.Response, data [][]byte) error {
 
+	// check status and headers
+	accepted, err := rp.check(resp, data)
+	if err != nil || accepted {
+		return err
+	}
+
+	// process body
+	if err := rp.ctx.Receiver(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// check validates the status and runs header processors, reporting accepted errors that skip the body
+func (rp responseProcessor) check(resp *http.Response, data [][]byte) (bool, error) {
+
 	// check status
 	if rp.ctx.StatusChecker(resp) {
-		return nil
+		return true, nil
 	}
 	if rp.isErrorCode(resp.StatusCode) {
-		httpError := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-		if data != nil {
-			httpError = fmt.Errorf("unexpected status code: %d - %s", resp.StatusCode, string(data[0]))
+		var body []byte
+		if len(data) > 0 {
+			body = data[len(data)-1]
 		}
-		return httpError
+		return false, NewHTTPError(resp, body, nil)
 	}
 
 	// process headers
 	for _, processor := range rp.ctx.ResponseProcessors {
 		if err := processor(resp); err != nil {
-			return err
+			return false, err
 		}
 	}
 
-	// process body
-	if err := rp.ctx.Receiver(data); err != nil {
-		return err
-	}
-
-	return nil
+	return false, nil
 }
 
 func (rp responseProcessor) isErrorCode(code int) bool {
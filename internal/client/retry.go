@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+type RetryDecider func(resp *http.Response, err error, attempt int) bool
+
+type RetryPolicy struct {
+
+	// MaxAttempts limits the total number of attempts, including the first one
+	MaxAttempts int
+
+	// BaseDelay and MaxDelay bound the exponential backoff between attempts (zero MaxDelay means no cap)
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// MaxElapsed stops retrying once the next wait would exceed it (zero means no limit)
+	MaxElapsed time.Duration
+
+	// RetryNonIdempotent allows retrying methods like POST and PATCH
+	RetryNonIdempotent bool
+
+	// ShouldRetry decides if a failed attempt is retried
+	ShouldRetry RetryDecider
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		MaxElapsed:  30 * time.Second,
+		ShouldRetry: DefaultRetryDecider,
+	}
+}
+
+func DefaultRetryDecider(resp *http.Response, err error, attempt int) bool {
+
+	// transport errors and attempt timeouts, unless cancelled or already classified
+	if err != nil {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			return false
+		}
+		if errors.Is(err, ErrTimeout) {
+			return true
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) || errors.Is(err, ErrPinMismatch) {
+			return false
+		}
+		return true
+	}
+
+	// transient upstream failures
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (p RetryPolicy) Retryable(req *http.Request, resp *http.Response, err error, attempt int) bool {
+	if !p.RetryNonIdempotent && !isIdempotent(req) {
+		return false
+	}
+	if p.ShouldRetry == nil {
+		return DefaultRetryDecider(resp, err, attempt)
+	}
+	return p.ShouldRetry(resp, err, attempt)
+}
+
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+
+	// exponential ceiling, uncapped without MaxDelay but kept from overflowing
+	ceiling := p.BaseDelay
+	for i := 1; i < attempt && ceiling <= math.MaxInt64/2; i++ {
+		if p.MaxDelay > 0 && ceiling >= p.MaxDelay {
+			break
+		}
+		ceiling *= 2
+	}
+	if p.MaxDelay > 0 && ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	// full jitter
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	if req.Header.Get("Idempotency-Key") != "" {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestContext(t *testing.T, method, url string) RequestContext {
+	req, err := http.NewRequest(method, url, nil)
+	assert.NoError(t, err)
+	return RequestContext{
+		Method:        method,
+		Endpoint:      url,
+		Req:           req,
+		RetryPolicy:   RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+		StatusChecker: func(resp *http.Response) bool { return false },
+		Receiver:      func(payload [][]byte) error { return nil },
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt := 1; attempt < 10; attempt++ {
+		delay := policy.Backoff(attempt)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, time.Second)
+	}
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.Backoff(3))
+
+	// grows without a cap
+	uncapped := RetryPolicy{BaseDelay: 100 * time.Millisecond}
+	var longest time.Duration
+	for i := 0; i < 50; i++ {
+		longest = max(longest, uncapped.Backoff(6))
+	}
+	assert.Greater(t, longest, 100*time.Millisecond)
+	assert.LessOrEqual(t, longest, 3200*time.Millisecond)
+	assert.GreaterOrEqual(t, uncapped.Backoff(200), time.Duration(0))
+
+}
+
+func TestRetryPolicy(t *testing.T) {
+
+	var calls atomic.Int32
+	statuses := []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		if r.URL.Path == "/bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(statuses[n%len(statuses)])
+	}))
+	defer server.Close()
+
+	// transient failures are retried
+	{
+		calls.Store(0)
+		err := NewRunner(newTestContext(t, http.MethodGet, server.URL+"/flaky")).DoRequest()
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), calls.Load())
+	}
+
+	// client errors are not retried
+	{
+		calls.Store(0)
+		err := NewRunner(newTestContext(t, http.MethodGet, server.URL+"/bad")).DoRequest()
+		assert.ErrorIs(t, err, ErrBadRequest)
+		assert.NotErrorIs(t, err, ErrRetriesExhausted)
+		assert.Equal(t, int32(1), calls.Load())
+	}
+
+	// non-idempotent methods are not retried by default
+	{
+		calls.Store(0)
+		err := NewRunner(newTestContext(t, http.MethodPost, server.URL+"/flaky")).DoRequest()
+		assert.ErrorIs(t, err, ErrServerError)
+		assert.Equal(t, int32(1), calls.Load())
+	}
+
+	// exhausted attempts are reported
+	{
+		calls.Store(0)
+		ctx := newTestContext(t, http.MethodPost, server.URL+"/flaky")
+		ctx.RetryPolicy.MaxAttempts = 2
+		ctx.RetryPolicy.RetryNonIdempotent = true
+		err := NewRunner(ctx).DoRequest()
+		assert.True(t, errors.Is(err, ErrRetriesExhausted))
+		assert.Equal(t, int32(2), calls.Load())
+	}
+
+}
@@ -1,11 +1,16 @@
 package client
 
 import (
-	"crypto/tls"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"net"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -16,6 +21,9 @@ type RequestRunner struct {
 type requester func(req *http.Request) (*http.Response, error)
 
 func NewRunner(ctx RequestContext) *RequestRunner {
+	if ctx.Context == nil {
+		ctx.Context = context.Background()
+	}
 	return &RequestRunner{
 		ctx: ctx,
 	}
@@ -23,45 +31,254 @@ func NewRunner(ctx RequestContext) *RequestRunner {
 
 func (r RequestRunner) DoRequest() error {
 
+	// prepare
+	exe, cancel, err := r.prepare()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	// execute
+	resp, data, err := r.pagedConsume(exe)
+	if err != nil {
+		return asTimeout(err)
+	}
+
+	// process response
+	rp := responseProcessor{
+		ctx: r.ctx,
+	}
+	if err := rp.process(resp, data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r RequestRunner) Pages() iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+
+		// prepare
+		exe, cancel, err := r.prepare()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer cancel()
+		paginator := r.ctx.Paginator
+		if paginator == nil {
+			paginator = singlePaginator{}
+		}
+
+		// fetch lazily, page by page
+		rp := responseProcessor{
+			ctx: r.ctx,
+		}
+		var visitErr error
+		stopped := false
+		err = r.walkPages(exe, paginator, func(res *http.Response, items []byte) bool {
+
+			// check status and headers
+			accepted, err := rp.check(res, [][]byte{items})
+			if err != nil {
+				visitErr = err
+				return false
+			}
+			if accepted {
+				return false
+			}
+
+			// hand out page
+			if !yield(items, nil) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if stopped {
+			return
+		}
+		if err == nil {
+			err = visitErr
+		}
+		if err != nil {
+			yield(nil, asTimeout(err))
+		}
+	}
+}
+
+func (r *RequestRunner) prepare() (requester, context.CancelFunc, error) {
+
 	// apply defaults
 	for _, opt := range r.ctx.DefaultOptions {
 		if err := opt(&r.ctx); err != nil {
-			return err
+			return nil, nil, err
 		}
 	}
 
+	// capture payload for replay across attempts and pages
+	if err := makeReplayable(r.ctx.Req); err != nil {
+		return nil, nil, err
+	}
+
 	// prepare
-	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: r.ctx.SkipTLSVerify},
-		},
+	client, err := r.httpClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// bound the whole run, across retries and pages; set before the middleware copies the context
+	cancel := context.CancelFunc(func() {})
+	if r.ctx.Timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(r.ctx.Context, r.ctx.Timeout)
+		r.ctx.Context = ctx
+		r.ctx.Req = r.ctx.Req.WithContext(ctx)
 	}
+
 	exe := func(req *http.Request) (*http.Response, error) {
-		return client.Do(r.ctx.Req)
+		attemptReq, err := cloneRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, limiter := range r.ctx.RateLimiters {
+			if err := limiter.Wait(attemptReq); err != nil {
+				return nil, err
+			}
+		}
+		if r.ctx.AttemptTimeout > 0 {
+			return r.timedAttempt(client, attemptReq)
+		}
+		return client.Do(attemptReq)
 	}
 
 	// runner middleware
+	exe = r.authenticate(exe)
 	exe = r.autoThrottle(exe)
 	exe = r.autoRetry(exe)
 
-	// execute
-	resp, data, err := r.pagedConsume(exe)
-	if err != nil {
-		return err
+	return exe, cancel, nil
+}
+
+// timedAttempt bounds a single attempt, including reading the response body
+func (r RequestRunner) timedAttempt(client *http.Client, req *http.Request) (*http.Response, error) {
+
+	ctx, cancel := context.WithTimeout(req.Context(), r.ctx.AttemptTimeout)
+	defer cancel()
+
+	// run and buffer, as the body is unreadable once cancelled
+	resp, err := client.Do(req.WithContext(ctx))
+	if err == nil {
+		var data []byte
+		data, err = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+	if err == nil {
+		return resp, nil
 	}
 
-	// process response
-	rp := responseProcessor{
-		ctx: r.ctx,
+	// tell attempt timeouts from cancelled runs
+	if ctx.Err() != nil && req.Context().Err() == nil {
+		return nil, fmt.Errorf("%w: attempt exceeded %s: %w", ErrTimeout, r.ctx.AttemptTimeout, err)
 	}
-	if err := rp.process(resp, data); err != nil {
-		return err
+	return nil, err
+}
+
+func (r RequestRunner) httpClient() (*http.Client, error) {
+
+	// transport settings cannot apply to injected clients or transports
+	if r.ctx.Transport != (TransportConfig{}) && (r.ctx.HTTPClient != nil || r.ctx.RoundTripper != nil) {
+		return nil, fmt.Errorf("tls and connection settings cannot be combined with a custom http client or transport")
 	}
 
-	return nil
+	// injected client
+	if r.ctx.HTTPClient != nil {
+		client := *r.ctx.HTTPClient
+		if client.CheckRedirect == nil || r.ctx.RedirectPolicy.MaxRedirects > 0 {
+			client.CheckRedirect = r.ctx.RedirectPolicy.CheckRedirect
+		}
+		if r.ctx.Jar != nil {
+			client.Jar = r.ctx.Jar
+		}
+		return &client, nil
+	}
+
+	// injected or pooled transport
+	transport := r.ctx.RoundTripper
+	if transport == nil {
+		pool := r.ctx.Transports
+		if pool == nil {
+			pool = sharedTransports
+		}
+		pooled, err := pool.Get(r.ctx.Transport)
+		if err != nil {
+			return nil, err
+		}
+		transport = pooled
+	}
+
+	return &http.Client{
+		CheckRedirect: r.ctx.RedirectPolicy.CheckRedirect,
+		Transport:     transport,
+		Jar:           r.ctx.Jar,
+	}, nil
+}
+
+func (r RequestRunner) authenticate(rq requester) requester {
+
+	authenticators := r.ctx.Authenticators
+	if len(authenticators) == 0 {
+		return rq
+	}
+
+	mw := func(req *http.Request) (*http.Response, error) {
+		for attempt := 1; ; attempt++ {
+
+			// authorize attempt
+			authReq := req.Clone(req.Context())
+			for _, auth := range authenticators {
+				if err := auth.Authenticate(authReq); err != nil {
+					return nil, err
+				}
+			}
+			authReq = markCredentials(authReq, req.Header)
+
+			// run request
+			resp, err := rq(authReq)
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode != http.StatusUnauthorized || attempt > 1 {
+				return resp, nil
+			}
+
+			// answer challenge
+			retry := false
+			for _, auth := range authenticators {
+				challenger, ok := auth.(Challenger)
+				if !ok {
+					continue
+				}
+				answered, err := challenger.Challenge(authReq, resp)
+				if err != nil {
+					_ = resp.Body.Close()
+					return nil, err
+				}
+				retry = retry || answered
+			}
+			if !retry {
+				return resp, nil
+			}
+
+			// retry once
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+	}
+
+	return mw
+
 }
 
 func (r RequestRunner) autoThrottle(rq requester) requester {
@@ -86,8 +303,12 @@ func (r RequestRunner) autoThrottle(rq requester) requester {
 				// limit attempts
 				attempts--
 				if attempts <= 0 {
-					return nil, fmt.Errorf("too many attempts")
+					body, _ := io.ReadAll(resp.Body)
+					_ = resp.Body.Close()
+					return nil, NewHTTPError(resp, body, ErrRetriesExhausted)
 				}
+				_, _ = io.Copy(io.Discard, resp.Body)
+				_ = resp.Body.Close()
 
 				// wait and retry
 				after := resp.Header.Get("Retry-After")
@@ -97,7 +318,9 @@ func (r RequestRunner) autoThrottle(rq requester) requester {
 				} else if seconds <= 0 {
 					seconds = 1
 				}
-				time.Sleep(time.Duration(seconds) * time.Second)
+				if err := r.sleep(time.Duration(seconds) * time.Second); err != nil {
+					return nil, err
+				}
 				continue
 
 			}
@@ -113,46 +336,54 @@ func (r RequestRunner) autoThrottle(rq requester) requester {
 
 func (r RequestRunner) autoRetry(rq requester) requester {
 
-	if r.ctx.AutoRetries <= 0 {
+	policy := r.ctx.RetryPolicy
+	if policy.MaxAttempts <= 1 {
 		return rq
 	}
 
 	mw := func(req *http.Request) (*http.Response, error) {
-		attempts := r.ctx.AutoRetries
-		var reqErr error
-		for {
+		started := time.Now()
+		for attempt := 1; ; attempt++ {
 
 			// run request
 			resp, err := rq(req)
 			if err != nil {
-				runnerErr := fmt.Errorf("failed to execute request [%s] - last error: %v", req.URL.String(), reqErr)
-				return nil, runnerErr
-			}
-
-			// check if failed (can be rate limit)
-			if resp.StatusCode == http.StatusBadRequest {
-
-				// gather error
-				data, bodyErr := io.ReadAll(resp.Body)
-				if bodyErr == nil {
-					reqErr = fmt.Errorf("%s", string(data))
+				if ctxErr := r.ctx.Context.Err(); ctxErr != nil {
+					return nil, ctxErr
 				}
+			}
 
-				// limit attempts
-				attempts--
-				if attempts <= 0 {
-					runnerErr := fmt.Errorf("too many failed attempts for [%s] - last error: %v", req.URL.String(), reqErr)
-					return nil, runnerErr
+			// done if not retryable
+			if !policy.Retryable(req, resp, err, attempt) {
+				if err != nil {
+					return nil, fmt.Errorf("failed to execute request [%s]: %w", req.URL.String(), err)
 				}
+				return resp, nil
+			}
 
-				// wait and retry
-				time.Sleep(time.Duration(1) * time.Second)
-				continue
+			// gather error
+			var lastErr error
+			if err != nil {
+				lastErr = fmt.Errorf("%w: failed to execute request [%s]: %w", ErrRetriesExhausted, req.URL.String(), err)
+			} else {
+				data, _ := io.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+				lastErr = NewHTTPError(resp, data, ErrRetriesExhausted)
+			}
 
+			// limit attempts
+			if attempt >= policy.MaxAttempts {
+				return nil, lastErr
+			}
+			delay := policy.Backoff(attempt)
+			if policy.MaxElapsed > 0 && time.Since(started)+delay > policy.MaxElapsed {
+				return nil, lastErr
 			}
 
-			// done
-			return resp, nil
+			// wait and retry
+			if err := r.sleep(delay); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -181,64 +412,214 @@ func (r RequestRunner) directConsume(rq requester) (*http.Response, [][]byte, er
 func (r RequestRunner) pagedConsume(rq requester) (*http.Response, [][]byte, error) {
 
 	// no paging
-	if !r.ctx.Paging.ConsumeAll {
+	paginator := r.ctx.Paginator
+	if paginator == nil {
 		return r.directConsume(rq)
 	}
 
-	// start consuming at first page
-	page := 1
+	// consume remaining pages concurrently, if known upfront
+	if ranger, ok := paginator.(PageRanger); ok && r.ctx.PageConcurrency > 1 {
+		return r.concurrentConsume(rq, paginator, ranger)
+	}
+
+	// consume all pages
 	var res *http.Response
 	var combinedData [][]byte
-	for {
+	err := r.walkPages(rq, paginator, func(pageRes *http.Response, items []byte) bool {
+		res = pageRes
+		combinedData = append(combinedData, items)
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return res, combinedData, nil
+}
 
-		// set page param
-		values := r.ctx.Req.URL.Query()
-		values.Set(r.ctx.Paging.PageParam, fmt.Sprintf("%d", page))
-		r.ctx.Req.URL.RawQuery = values.Encode()
+func (r RequestRunner) concurrentConsume(rq requester, paginator Paginator, ranger PageRanger) (*http.Response, [][]byte, error) {
 
-		// read response
-		pageRes, err := rq(r.ctx.Req)
+	// read first page
+	req, err := paginator.First(r.ctx.Req)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, body, err := r.fetchPage(rq, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.isErrorResponse(res) {
+		return res, [][]byte{body}, nil
+	}
+	items, err := paginator.Items(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	combinedData := [][]byte{items}
+
+	// gather remaining pages, continue one by one if unknown
+	remaining, ok, err := ranger.Remaining(req, res, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		next, err := paginator.Next(req, res, body)
 		if err != nil {
 			return nil, nil, err
 		}
-		body, err := io.ReadAll(pageRes.Body)
+		err = r.walkFrom(rq, paginator, next, func(pageRes *http.Response, items []byte) bool {
+			res = pageRes
+			combinedData = append(combinedData, items)
+			return true
+		})
 		if err != nil {
 			return nil, nil, err
 		}
-		if err := pageRes.Body.Close(); err != nil {
+		return res, combinedData, nil
+	}
+
+	// fetch with bounded concurrency, aborting the others on first failure
+	fetchCtx, cancel := context.WithCancel(r.ctx.Context)
+	defer cancel()
+	responses := make([]*http.Response, len(remaining))
+	bodies := make([][]byte, len(remaining))
+	errs := make([]error, len(remaining))
+	pages := make(chan int, len(remaining))
+	for i := range remaining {
+		pages <- i
+	}
+	close(pages)
+	var wg sync.WaitGroup
+	for range min(r.ctx.PageConcurrency, len(remaining)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pages {
+				if err := fetchCtx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				responses[i], bodies[i], errs[i] = r.fetchPage(rq, remaining[i].WithContext(fetchCtx))
+				if errs[i] != nil || r.isErrorResponse(responses[i]) {
+					cancel()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// combine in page order, first failure wins
+	for i := range remaining {
+		if errs[i] != nil {
+			if ctxErr := r.ctx.Context.Err(); ctxErr != nil {
+				return nil, nil, ctxErr
+			}
+			if errors.Is(errs[i], context.Canceled) {
+				continue
+			}
+			return nil, nil, errs[i]
+		}
+		if r.isErrorResponse(responses[i]) {
+			return responses[i], [][]byte{bodies[i]}, nil
+		}
+	}
+	for i := range remaining {
+		items, err := paginator.Items(bodies[i])
+		if err != nil {
 			return nil, nil, err
 		}
+		combinedData = append(combinedData, items)
+		res = responses[i]
+	}
 
-		// combine
-		combinedData = append(combinedData, body)
-		res = pageRes
+	return res, combinedData, nil
+}
 
-		// handle paging
-		totalPages, ok, err := r.getPageCount(pageRes)
-		if !ok {
-			break
+// walkPages fetches pages in order and hands each to visit, until visit declines or paging ends;
+// error responses are handed over as they are and end paging
+func (r RequestRunner) walkPages(rq requester, paginator Paginator, visit func(res *http.Response, items []byte) bool) error {
+	req, err := paginator.First(r.ctx.Req)
+	if err != nil {
+		return err
+	}
+	return r.walkFrom(rq, paginator, req, visit)
+}
+
+func (r RequestRunner) walkFrom(rq requester, paginator Paginator, req *http.Request, visit func(res *http.Response, items []byte) bool) error {
+	for req != nil {
+
+		// stop if cancelled
+		if err := r.ctx.Context.Err(); err != nil {
+			return err
 		}
+
+		// read response
+		pageRes, body, err := r.fetchPage(rq, req)
 		if err != nil {
-			return nil, nil, err
+			return err
 		}
-		if page >= totalPages {
-			break
+		if r.isErrorResponse(pageRes) {
+			visit(pageRes, body)
+			return nil
+		}
+
+		// hand out items
+		items, err := paginator.Items(body)
+		if err != nil {
+			return err
+		}
+		if !visit(pageRes, items) {
+			return nil
+		}
+
+		// handle paging
+		req, err = paginator.Next(req, pageRes, body)
+		if err != nil {
+			return err
 		}
-		page++
 	}
 
-	return res, combinedData, nil
+	return nil
 }
 
-func (r RequestRunner) getPageCount(res *http.Response) (int, bool, error) {
+func (r RequestRunner) isErrorResponse(res *http.Response) bool {
+	return res.StatusCode < 200 || res.StatusCode > 299
+}
 
-	exp := res.Header.Get(r.ctx.Paging.PageCountHeader)
-	if exp == "" {
-		return 0, false, nil
+func (r RequestRunner) fetchPage(rq requester, req *http.Request) (*http.Response, []byte, error) {
+	res, err := rq(req)
+	if err != nil {
+		return nil, nil, err
 	}
-	pageCount, err := strconv.Atoi(exp)
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return 0, false, err
+		return nil, nil, err
+	}
+	if err := res.Body.Close(); err != nil {
+		return nil, nil, err
+	}
+	return res, body, nil
+}
+
+func (r RequestRunner) sleep(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-r.ctx.Context.Done():
+		return r.ctx.Context.Err()
+	}
+}
+
+// asTimeout marks deadline and network timeouts with ErrTimeout
+func asTimeout(err error) error {
+	if errors.Is(err, ErrTimeout) {
+		return err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
 	}
-	return pageCount, true, nil
+	return err
 }
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// cancellingPaginator cancels the run once the first page arrived
+type cancellingPaginator struct {
+	LinkPaginator
+	cancel context.CancelFunc
+}
+
+func (p cancellingPaginator) Next(req *http.Request, resp *http.Response, body []byte) (*http.Request, error) {
+	p.cancel()
+	return p.LinkPaginator.Next(req, resp, body)
+}
+
+func newCancelContext(t *testing.T, url string) (RequestContext, context.CancelFunc) {
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	ctx := newTestContext(t, http.MethodGet, url)
+	ctx.Context = cancelCtx
+	ctx.Req = ctx.Req.WithContext(cancelCtx)
+	return ctx, cancel
+}
+
+func TestCancelWaits(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/throttled":
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	run := func(path string, policy RetryPolicy) error {
+		ctx, cancel := newCancelContext(t, server.URL+path)
+		defer cancel()
+		ctx.AutoThrottle = true
+		ctx.RetryPolicy = policy
+		time.AfterFunc(50*time.Millisecond, cancel)
+		return NewRunner(ctx).DoRequest()
+	}
+
+	// during Retry-After wait
+	started := time.Now()
+	assert.True(t, errors.Is(run("/throttled", RetryPolicy{}), context.Canceled))
+	assert.Less(t, time.Since(started), time.Second)
+
+	// during retry backoff
+	started = time.Now()
+	err := run("/unavailable", RetryPolicy{MaxAttempts: 100, BaseDelay: time.Second, MaxDelay: 3 * time.Second})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Less(t, time.Since(started), time.Second)
+
+}
+
+func TestCancelBetweenPages(t *testing.T) {
+
+	var fetched atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Add(1)
+		w.Header().Set("Link", `</items?page=next>; rel="next"`)
+		_, _ = fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	// collected
+	ctx, cancel := newCancelContext(t, server.URL+"/items")
+	defer cancel()
+	ctx.Paginator = cancellingPaginator{cancel: cancel}
+	err := NewRunner(ctx).DoRequest()
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, int32(1), fetched.Load())
+
+	// streamed
+	fetched.Store(0)
+	ctx, cancel = newCancelContext(t, server.URL+"/items")
+	defer cancel()
+	ctx.Paginator = LinkPaginator{}
+	var errs []error
+	for _, err := range NewRunner(ctx).Pages() {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		cancel()
+	}
+	if assert.Len(t, errs, 1) {
+		assert.True(t, errors.Is(errs[0], context.Canceled))
+	}
+	assert.Equal(t, int32(1), fetched.Load())
+
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// CookieJar stores cookies like cookiejar.Jar, but remembers what it was given so it can be persisted
+type CookieJar struct {
+	path string
+
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	entries []cookieEntry
+
+	// err is the outcome of the last write to path
+	err error
+}
+
+type cookieEntry struct {
+	URL    string       `json:"url"`
+	Cookie *http.Cookie `json:"cookie"`
+}
+
+func (e cookieEntry) matches(u *url.URL, cookie *http.Cookie) bool {
+	entryURL, err := url.Parse(e.URL)
+	if err != nil {
+		return false
+	}
+	return entryURL.Host == u.Host && e.Cookie.Name == cookie.Name && e.Cookie.Path == cookie.Path && e.Cookie.Domain == cookie.Domain
+}
+
+// NewCookieJar creates a jar, persisted to path unless empty and restored from it if present
+func NewCookieJar(path string) (*CookieJar, error) {
+
+	j := &CookieJar{path: path}
+	if err := j.reset(); err != nil {
+		return nil, err
+	}
+
+	// restore
+	if path == "" {
+		return j, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []cookieEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		u, err := url.Parse(entry.URL)
+		if err != nil {
+			return nil, err
+		}
+		j.record(u, entry.Cookie)
+		j.jar.SetCookies(u, []*http.Cookie{entry.Cookie})
+	}
+
+	return j, nil
+}
+
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// store
+	j.jar.SetCookies(u, cookies)
+	for _, cookie := range cookies {
+		j.record(u, cookie)
+	}
+
+	// persist, keeping the error as cookie jars cannot report it
+	j.err = j.save()
+}
+
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.jar.Cookies(u)
+}
+
+// Clear drops all cookies, including persisted ones
+func (j *CookieJar) Clear() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.reset(); err != nil {
+		return err
+	}
+	j.err = j.save()
+	return j.err
+}
+
+// Err reports if the cookies could not be persisted the last time they changed
+func (j *CookieJar) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+func (j *CookieJar) reset() error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+	j.jar = jar
+	j.entries = nil
+	return nil
+}
+
+func (j *CookieJar) record(u *url.URL, cookie *http.Cookie) {
+
+	// fix relative lifetime
+	stored := *cookie
+	if stored.MaxAge > 0 {
+		stored.Expires = time.Now().Add(time.Duration(stored.MaxAge) * time.Second)
+		stored.MaxAge = 0
+	}
+
+	// replace previous
+	entries := j.entries[:0]
+	for _, entry := range j.entries {
+		if !entry.matches(u, &stored) {
+			entries = append(entries, entry)
+		}
+	}
+	j.entries = entries
+
+	// keep unless deleted or expired
+	if stored.MaxAge < 0 || (!stored.Expires.IsZero() && stored.Expires.Before(time.Now())) {
+		return
+	}
+	origin := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}
+	j.entries = append(j.entries, cookieEntry{URL: origin.String(), Cookie: &stored})
+}
+
+func (j *CookieJar) save() error {
+	if j.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(j.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(j.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to persist cookies: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCookieJarSession(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t", Path: "/", MaxAge: 3600})
+			http.SetCookie(w, &http.Cookie{Name: "flash", Value: "hello", Path: "/"})
+		case "/logout":
+			http.SetCookie(w, &http.Cookie{Name: "flash", Path: "/", MaxAge: -1})
+		default:
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "s3cr3t" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	jar, err := NewCookieJar(path)
+	assert.NoError(t, err)
+	run := func(jar http.CookieJar, ep string) error {
+		ctx := newTestContext(t, http.MethodGet, server.URL+ep)
+		ctx.Jar = jar
+		return NewRunner(ctx).DoRequest()
+	}
+
+	// cookies are stored and sent
+	assert.ErrorIs(t, run(jar, "/me"), ErrUnauthorized)
+	assert.NoError(t, run(jar, "/login"))
+	assert.NoError(t, run(jar, "/me"))
+	assert.NoError(t, run(jar, "/logout"))
+	assert.Equal(t, 1, len(jar.Cookies(serverURL)))
+	assert.NoError(t, jar.Err())
+
+	// restored from file
+	restored, err := NewCookieJar(path)
+	assert.NoError(t, err)
+	assert.NoError(t, run(restored, "/me"))
+	assert.Equal(t, "s3cr3t", restored.Cookies(serverURL)[0].Value)
+
+	// cleared
+	assert.NoError(t, restored.Clear())
+	assert.ErrorIs(t, run(restored, "/me"), ErrUnauthorized)
+	cleared, err := NewCookieJar(path)
+	assert.NoError(t, err)
+	assert.Empty(t, cleared.Cookies(serverURL))
+
+}
+
+func TestCookieJarUnwritable(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t", Path: "/"})
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+
+	// directory does not exist
+	jar, err := NewCookieJar(filepath.Join(t.TempDir(), "missing", "session.json"))
+	assert.NoError(t, err)
+	ctx := newTestContext(t, http.MethodGet, server.URL)
+	ctx.Jar = jar
+	assert.NoError(t, NewRunner(ctx).DoRequest())
+
+	// cookies still usable, failure kept
+	assert.Equal(t, "s3cr3t", jar.Cookies(serverURL)[0].Value)
+	assert.ErrorIs(t, jar.Err(), os.ErrNotExist)
+	assert.ErrorIs(t, jar.Clear(), os.ErrNotExist)
+
+}
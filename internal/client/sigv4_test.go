@@ -0,0 +1,166 @@
+package client
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// vectors from the AWS Signature Version 4 test suite
+func TestSigV4TestSuite(t *testing.T) {
+
+	signedAt := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	credential := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request"
+
+	vectors := []struct {
+		name          string
+		method        string
+		url           string
+		headers       map[string]string
+		body          string
+		authorization string
+
+		// the suite encodes paths once, as S3 does
+		singleEncoded bool
+	}{
+		{
+			name:          "get-vanilla",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/",
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "post-vanilla",
+			method:        http.MethodPost,
+			url:           "https://example.amazonaws.com/",
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:          "get-vanilla-query-order-key-case",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:          "post-x-www-form-urlencoded",
+			method:        http.MethodPost,
+			url:           "https://example.amazonaws.com/",
+			headers:       map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:          "Param1=value1",
+			authorization: credential + ", SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		{
+			name:          "get-slash",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com//",
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "get-slashes",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com//example//",
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=9a624bd73a37c9a373b5312afbebe7a714a789de108f0bdfe846570885f57e84",
+		},
+		{
+			name:          "get-relative",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/example/..",
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "get-relative-relative",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/example1/example2/../..",
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "get-slash-dot-slash",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/./",
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "get-slash-pointless-dot",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/./example",
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=ef75d96142cf21edca26f06005da7988e4f8dc83a165a80865db7089db637ec5",
+		},
+		{
+			name:          "get-space",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/example space/",
+			singleEncoded: true,
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=652487583200325589f1fba4c7e578f72c47cb61beeca81406b39ddec1366741",
+		},
+		{
+			name:          "get-utf8",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/ሴ",
+			singleEncoded: true,
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=8318018e0b0f223aa2bbf98705b62bb787dc9c0e678f255a891fd03141be5d85",
+		},
+		{
+			name:          "post-header-key-case",
+			method:        http.MethodPost,
+			url:           "https://example.amazonaws.com/",
+			headers:       map[string]string{"X-AMZ-DATE": "20150830T123600Z"},
+			authorization: credential + ", SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+	}
+
+	for _, vector := range vectors {
+		t.Run(vector.name, func(t *testing.T) {
+
+			var body io.Reader
+			if vector.body != "" {
+				body = strings.NewReader(vector.body)
+			}
+			req, err := http.NewRequest(vector.method, vector.url, body)
+			assert.NoError(t, err)
+			for key, value := range vector.headers {
+				req.Header.Set(key, value)
+			}
+
+			signer := NewSigV4Signer("AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "", "us-east-1", "service")
+			signer.now = func() time.Time { return signedAt }
+			signer.DisableURIPathEscaping = vector.singleEncoded
+			assert.NoError(t, signer.Authenticate(req))
+
+			assert.Equal(t, vector.authorization, req.Header.Get("Authorization"))
+			assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+		})
+	}
+
+}
+
+func TestSigV4UnsignedPayload(t *testing.T) {
+
+	req, err := http.NewRequest(http.MethodPut, "http://localhost:9000/bucket/my%20key.txt", strings.NewReader("data"))
+	assert.NoError(t, err)
+
+	signer := NewSigV4Signer("minio", "minio123", "session", "us-east-1", "s3")
+	signer.UnsignedPayload = true
+	assert.NoError(t, signer.Authenticate(req))
+
+	assert.Equal(t, "UNSIGNED-PAYLOAD", req.Header.Get("X-Amz-Content-Sha256"))
+	assert.Equal(t, "session", req.Header.Get("X-Amz-Security-Token"))
+	assert.Contains(t, req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,")
+	assert.Equal(t, "/bucket/my%20key.txt", signer.canonicalURI(req.URL))
+
+	// other services encode the escaped path once more
+	signer.Service = "execute-api"
+	signer.DisableURIPathEscaping = false
+	assert.Equal(t, "/bucket/my%2520key.txt", signer.canonicalURI(req.URL))
+
+	// S3 keys are not normalized
+	dotted, err := url.Parse("http://localhost:9000/bucket//a/../b")
+	assert.NoError(t, err)
+	signer.Service = "s3"
+	signer.DisableURIPathEscaping = true
+	assert.Equal(t, "/bucket//a/../b", signer.canonicalURI(dotted))
+
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTimeouts(t *testing.T) {
+
+	// stalls on first call, or always if asked to
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		first := calls.Add(1) == 1
+		switch r.URL.Path {
+		case "/stall":
+			if first || r.URL.Query().Get("always") != "" {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/slow-body":
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			if first {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			}
+			_, _ = w.Write([]byte("done"))
+		}
+	}))
+	defer server.Close()
+
+	run := func(path string, configure func(ctx *RequestContext)) (string, error) {
+		calls.Store(0)
+		ctx := newTestContext(t, http.MethodGet, server.URL+path)
+		ctx.Transports = NewTransportPool()
+		var received string
+		ctx.Receiver = func(payload [][]byte) error {
+			received = string(MergePages(payload))
+			return nil
+		}
+		configure(&ctx)
+		return received, NewRunner(ctx).DoRequest()
+	}
+
+	// stalled attempt retried
+	_, err := run("/stall", func(ctx *RequestContext) { ctx.AttemptTimeout = 50 * time.Millisecond })
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// stalled body retried
+	received, err := run("/slow-body", func(ctx *RequestContext) { ctx.AttemptTimeout = 50 * time.Millisecond })
+	assert.NoError(t, err)
+	assert.Equal(t, "done", received)
+
+	// total deadline
+	started := time.Now()
+	_, err = run("/stall?always=1", func(ctx *RequestContext) {
+		ctx.Timeout = 100 * time.Millisecond
+		ctx.AttemptTimeout = 50 * time.Millisecond
+	})
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(started), 500*time.Millisecond)
+
+	// transport phase
+	_, err = run("/stall?always=1", func(ctx *RequestContext) {
+		ctx.RetryPolicy = RetryPolicy{}
+		ctx.Transport.ResponseHeaderTimeout = 50 * time.Millisecond
+	})
+	assert.True(t, errors.Is(err, ErrTimeout))
+
+	// cancellation is no timeout
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = run("/stall", func(ctx *RequestContext) {
+		ctx.Context = cancelled
+		ctx.Req = ctx.Req.WithContext(cancelled)
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, errors.Is(err, ErrTimeout))
+
+}
+
+func TestTimeoutBoundsWaits(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/throttled":
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	run := func(path string, policy RetryPolicy) error {
+		ctx := newTestContext(t, http.MethodGet, server.URL+path)
+		ctx.AutoThrottle = true
+		ctx.RetryPolicy = policy
+		ctx.Timeout = 100 * time.Millisecond
+		return NewRunner(ctx).DoRequest()
+	}
+
+	// throttle wait
+	started := time.Now()
+	err := run("/throttled", RetryPolicy{})
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.Less(t, time.Since(started), time.Second)
+
+	// retry backoff
+	started = time.Now()
+	err = run("/unavailable", RetryPolicy{MaxAttempts: 100, BaseDelay: time.Second, MaxDelay: 3 * time.Second})
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.Less(t, time.Since(started), time.Second)
+
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var sharedTransports = NewTransportPool()
+
+// TransportConfig describes a transport; it holds comparable values only, so it can key the pool
+type TransportConfig struct {
+	SkipTLSVerify bool
+	MinTLSVersion uint16
+
+	// PEM encoded client certificate and key for mutual TLS
+	ClientCertPEM string
+	ClientKeyPEM  string
+
+	// PEM encoded CA certificates trusted in addition to the system pool
+	RootCAsPEM string
+
+	// comma-separated SHA-256 fingerprints (hex) of accepted server certificates
+	PinnedSHA256 string
+
+	// connection phase timeouts, zero keeps the defaults
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+}
+
+type TransportPool struct {
+	mu         sync.Mutex
+	transports map[TransportConfig]*http.Transport
+}
+
+func NewTransportPool() *TransportPool {
+	return &TransportPool{
+		transports: map[TransportConfig]*http.Transport{},
+	}
+}
+
+func (p *TransportPool) Get(cfg TransportConfig) (*http.Transport, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// reuse
+	if transport, ok := p.transports[cfg]; ok {
+		return transport, nil
+	}
+
+	// create
+	transport, err := cfg.build()
+	if err != nil {
+		return nil, err
+	}
+	p.transports[cfg] = transport
+
+	return transport, nil
+}
+
+func (p *TransportPool) CloseIdleConnections() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, transport := range p.transports {
+		transport.CloseIdleConnections()
+	}
+}
+
+func (cfg TransportConfig) build() (*http.Transport, error) {
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.SkipTLSVerify,
+		MinVersion:         cfg.MinTLSVersion,
+	}
+
+	// client certificate
+	if cfg.ClientCertPEM != "" || cfg.ClientKeyPEM != "" {
+		cert, err := tls.X509KeyPair([]byte(cfg.ClientCertPEM), []byte(cfg.ClientKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// custom CAs
+	if cfg.RootCAsPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(cfg.RootCAsPEM)) {
+			return nil, fmt.Errorf("invalid CA bundle: no certificates found")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// pinning
+	if cfg.PinnedSHA256 != "" {
+		pins := map[string]bool{}
+		for _, pin := range strings.Split(cfg.PinnedSHA256, ",") {
+			pins[pin] = true
+		}
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			for _, cert := range cs.PeerCertificates {
+				fingerprint := sha256.Sum256(cert.Raw)
+				if pins[hex.EncodeToString(fingerprint[:])] {
+					return nil
+				}
+			}
+			return fmt.Errorf("%w: no pinned certificate presented by %s", ErrPinMismatch, cs.ServerName)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	// timeouts
+	if cfg.DialTimeout > 0 {
+		dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	}
+
+	return transport, nil
+}
+
+// NormalizeFingerprint turns "AB:CD:..." or "abcd..." notations into lowercase hex
+func NormalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+}
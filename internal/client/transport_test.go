@@ -0,0 +1,106 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTransportTLS(t *testing.T) {
+
+	// server requiring a client certificate
+	clientCertPEM, clientKeyPEM, clientCert := newTestCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCAPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+	run := func(cfg TransportConfig) error {
+		ctx := newTestContext(t, http.MethodGet, server.URL)
+		ctx.Transport = cfg
+		ctx.Transports = NewTransportPool()
+		return NewRunner(ctx).DoRequest()
+	}
+
+	// trusted CA and client certificate
+	cfg := TransportConfig{
+		RootCAsPEM:    serverCAPEM,
+		ClientCertPEM: clientCertPEM,
+		ClientKeyPEM:  clientKeyPEM,
+		MinTLSVersion: tls.VersionTLS12,
+	}
+	assert.NoError(t, run(cfg))
+
+	// unknown CA
+	assert.Error(t, run(TransportConfig{ClientCertPEM: clientCertPEM, ClientKeyPEM: clientKeyPEM}))
+
+	// missing client certificate
+	assert.Error(t, run(TransportConfig{RootCAsPEM: serverCAPEM}))
+
+	// pinned certificate
+	cfg.PinnedSHA256 = hex.EncodeToString(fingerprint[:])
+	assert.NoError(t, run(cfg))
+	cfg.PinnedSHA256 = hex.EncodeToString(make([]byte, 32))
+	err := run(cfg)
+	assert.True(t, errors.Is(err, ErrPinMismatch))
+	assert.False(t, DefaultRetryDecider(nil, err, 1))
+
+}
+
+func TestTransportPoolReuse(t *testing.T) {
+
+	pool := NewTransportPool()
+	first, err := pool.Get(TransportConfig{})
+	assert.NoError(t, err)
+	second, err := pool.Get(TransportConfig{})
+	assert.NoError(t, err)
+	insecure, err := pool.Get(TransportConfig{SkipTLSVerify: true})
+	assert.NoError(t, err)
+
+	assert.Same(t, first, second)
+	assert.NotSame(t, first, insecure)
+
+}
+
+func newTestCertificate(t *testing.T) (string, string, *x509.Certificate) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM), cert
+}
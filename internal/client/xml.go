@@ -0,0 +1,87 @@
+package client
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// MergeXMLPages concatenates the child elements of each page's root into the root of the first page
+func MergeXMLPages(pages [][]byte) ([]byte, error) {
+
+	// empty
+	if len(pages) == 0 {
+		return nil, nil
+	}
+
+	// frame of first page
+	first := pages[0]
+	innerStart, innerEnd, name, err := xmlRootContent(first)
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	closing := first[innerEnd:]
+	selfClosing := innerStart == innerEnd && bytes.HasSuffix(first[:innerStart], []byte("/>"))
+	if selfClosing {
+
+		// expand empty root, e.g. <items/>
+		buffer.Write(bytes.TrimRight(first[:innerStart-2], " \t\r\n"))
+		buffer.WriteString(">")
+		closing = append([]byte("</"+name+">"), first[innerEnd:]...)
+	} else {
+		buffer.Write(first[:innerEnd])
+	}
+
+	// children of following pages
+	for _, page := range pages[1:] {
+		start, end, _, err := xmlRootContent(page)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(page[start:end])
+	}
+
+	// close root
+	buffer.Write(closing)
+
+	return buffer.Bytes(), nil
+}
+
+// xmlRootContent locates the raw content between the root element's start and end tags, along with its name;
+// for self-closing roots both offsets point right behind the element
+func xmlRootContent(raw []byte) (int, int, string, error) {
+
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	depth := 0
+	start := -1
+	name := ""
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			return 0, 0, "", fmt.Errorf("invalid xml page: missing root element")
+		}
+		if err != nil {
+			return 0, 0, "", err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				start = int(decoder.InputOffset())
+				name = element.Name.Local
+				if element.Name.Space != "" {
+					name = element.Name.Space + ":" + name
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				return start, offset, name, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMergeXMLPages(t *testing.T) {
+
+	pages := [][]byte{
+		[]byte(`<?xml version="1.0"?><items count="2"><item>v1</item><item>v2</item></items>`),
+		[]byte(`<items count="1"><item>v3</item></items>`),
+		[]byte(`<items/>`),
+	}
+	merged, err := MergeXMLPages(pages)
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0"?><items count="2"><item>v1</item><item>v2</item><item>v3</item></items>`, string(merged))
+
+	var data struct {
+		Items []string `xml:"item"`
+	}
+	err = xml.Unmarshal(merged, &data)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v1", "v2", "v3"}, data.Items)
+
+}
+
+func TestMergeXMLPagesEmptyFirstRoot(t *testing.T) {
+
+	var data struct {
+		Items []string `xml:"item"`
+	}
+	cases := map[string]string{
+		`<items count="0" />`:           `<items count="0"><item>a</item><item>b</item></items>`,
+		`<ns:items/>`:                   `<ns:items><item>a</item><item>b</item></ns:items>`,
+		`<items></items>`:               `<items><item>a</item><item>b</item></items>`,
+		`<?xml version="1.0"?><items/>`: `<?xml version="1.0"?><items><item>a</item><item>b</item></items>`,
+	}
+	for first, expected := range cases {
+		merged, err := MergeXMLPages([][]byte{
+			[]byte(first),
+			[]byte(`<items><item>a</item></items>`),
+			[]byte(`<items><item>b</item></items>`),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(merged))
+
+		data.Items = nil
+		assert.NoError(t, xml.Unmarshal(merged, &data))
+		assert.Equal(t, []string{"a", "b"}, data.Items)
+	}
+
+}
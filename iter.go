@@ -0,0 +1,86 @@
+package apimate
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/rollicks-c/apimate/internal/client"
+	"iter"
+	"net/http"
+)
+
+func Pages(c *Client, method, ep string, options ...client.RequestOption) iter.Seq2[[]byte, error] {
+	return PagesCtx(context.Background(), c, method, ep, options...)
+}
+
+func PagesCtx(ctx context.Context, c *Client, method, ep string, options ...client.RequestOption) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+
+		// fetch lazily
+		options, session := c.sessionOptions(options)
+		fetched, stopped := false, false
+		stream := func() error {
+			runner, err := c.newRunner(ctx, method, ep, options)
+			if err != nil {
+				return err
+			}
+			for page, err := range runner.Pages() {
+				if err != nil {
+					return err
+				}
+				fetched = true
+				if !yield(page, nil) {
+					stopped = true
+					return nil
+				}
+			}
+			return nil
+		}
+
+		// sign in again once if the session expired before the first page
+		err := stream()
+		if err != nil && !fetched {
+			var retry bool
+			if retry, err = c.relogin(ctx, session, err); retry {
+				err = stream()
+			}
+		}
+		if err != nil && !stopped {
+			yield(nil, err)
+		}
+	}
+}
+
+func Items[T any](c *Client, ep string, options ...client.RequestOption) iter.Seq2[T, error] {
+	return ItemsCtx[T](context.Background(), c, ep, options...)
+}
+
+func ItemsCtx[T any](ctx context.Context, c *Client, ep string, options ...client.RequestOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var empty T
+		for page, err := range PagesCtx(ctx, c, http.MethodGet, ep, options...) {
+			if err != nil {
+				yield(empty, err)
+				return
+			}
+
+			// split page into items
+			var items []json.RawMessage
+			if err := json.Unmarshal(page, &items); err != nil {
+				yield(empty, err)
+				return
+			}
+
+			// decode items as they arrive
+			for _, raw := range items {
+				var item T
+				if err := json.Unmarshal(raw, &item); err != nil {
+					yield(empty, err)
+					return
+				}
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package apimate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+type testItem struct {
+	ID int `json:"id"`
+}
+
+func newItemServer(t *testing.T, pages int, failPage int) (*httptest.Server, *atomic.Int32) {
+	var fetched atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		if page == failPage {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if page < pages {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=%d>; rel="next"`, r.Host, r.URL.Path, page+1))
+		}
+		_, _ = fmt.Fprintf(w, `[{"id": %d}, {"id": %d}]`, 2*page-1, 2*page)
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetched
+}
+
+func TestItems(t *testing.T) {
+
+	// decoded across pages
+	server, fetched := newItemServer(t, 3, 0)
+	c := New(server.URL)
+	var ids []int
+	for item, err := range Items[testItem](c, "items", WithLinkHeaderPaging()) {
+		assert.NoError(t, err)
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, ids)
+	assert.Equal(t, int32(3), fetched.Load())
+
+	// early break stops fetching
+	fetched.Store(0)
+	for item, err := range Items[testItem](c, "items", WithLinkHeaderPaging()) {
+		assert.NoError(t, err)
+		assert.Equal(t, 1, item.ID)
+		break
+	}
+	assert.Equal(t, int32(1), fetched.Load())
+
+}
+
+func TestItemsErrors(t *testing.T) {
+
+	// failing page ends the sequence with its error
+	server, _ := newItemServer(t, 3, 2)
+	c := New(server.URL)
+	var ids []int
+	var lastErr error
+	for item, err := range Items[testItem](c, "items", WithLinkHeaderPaging()) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []int{1, 2}, ids)
+	assert.True(t, errors.Is(lastErr, ErrNotFound))
+
+	// undecodable items
+	var errs []error
+	for _, err := range Items[string](c, "items") {
+		errs = append(errs, err)
+	}
+	if assert.Len(t, errs, 1) {
+		assert.Error(t, errs[0])
+	}
+
+	// cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	errs = nil
+	for _, err := range ItemsCtx[testItem](ctx, c, "items", WithLinkHeaderPaging()) {
+		errs = append(errs, err)
+	}
+	if assert.Len(t, errs, 1) {
+		assert.True(t, errors.Is(errs[0], context.Canceled))
+	}
+
+}
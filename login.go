@@ -0,0 +1,261 @@
+package apimate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rollicks-c/apimate/internal/client"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// FormLogin describes a classic HTML form login; empty fields fall back to common defaults
+type FormLogin struct {
+
+	// LoginPage is the endpoint serving the form, SubmitPath the one receiving it (defaults to LoginPage)
+	LoginPage  string
+	SubmitPath string
+
+	Username      string
+	Password      string
+	UsernameField string
+	PasswordField string
+
+	// CSRFField names the submitted token field; the token is scraped from a hidden input of the same
+	// name, the meta tag CSRFMeta or the cookie CSRFCookie
+	CSRFField  string
+	CSRFMeta   string
+	CSRFCookie string
+
+	// CSRFHeader optionally repeats the token in a header, e.g. X-CSRF-Token
+	CSRFHeader string
+
+	// Values are submitted along with the credentials
+	Values url.Values
+
+	// FailureMarker is a text only shown by a rejected login, e.g. "Invalid password"
+	FailureMarker string
+}
+
+type loginState struct {
+
+	// signIn serializes logins, mu guards the fields
+	signIn sync.Mutex
+	mu     sync.Mutex
+
+	form *FormLogin
+
+	// session counts successful logins
+	session int
+}
+
+func (s *loginState) current() (*FormLogin, int) {
+	if s == nil {
+		return nil, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.form, s.session
+}
+
+func (s *loginState) signedIn(form FormLogin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.form = &form
+	s.session++
+}
+
+// Login signs in through a login form and keeps doing so whenever a later request bounces to the login page
+func (c Client) Login(form FormLogin) error {
+	return c.LoginCtx(context.Background(), form)
+}
+
+func (c Client) LoginCtx(ctx context.Context, form FormLogin) error {
+
+	if c.jar == nil || c.login == nil {
+		return fmt.Errorf("form login requires a session client")
+	}
+
+	// defaults
+	if form.SubmitPath == "" {
+		form.SubmitPath = form.LoginPage
+	}
+	if form.UsernameField == "" {
+		form.UsernameField = "username"
+	}
+	if form.PasswordField == "" {
+		form.PasswordField = "password"
+	}
+
+	// sign in
+	c.login.signIn.Lock()
+	defer c.login.signIn.Unlock()
+	if err := c.submitLogin(ctx, form); err != nil {
+		return err
+	}
+	c.login.signedIn(form)
+
+	return nil
+}
+
+func (c Client) submitLogin(ctx context.Context, form FormLogin) error {
+
+	// fetch form
+	var page []byte
+	if err := c.request(ctx, http.MethodGet, form.LoginPage, []client.RequestOption{WithRawReceiver(&page)}); err != nil {
+		return fmt.Errorf("failed to fetch login page: %w", err)
+	}
+
+	// credentials
+	values := url.Values{}
+	for key, list := range form.Values {
+		values[key] = list
+	}
+	values.Set(form.UsernameField, form.Username)
+	values.Set(form.PasswordField, form.Password)
+
+	// csrf token
+	options := []client.RequestOption{WithValues(values)}
+	if form.CSRFField != "" || form.CSRFMeta != "" || form.CSRFCookie != "" {
+		token, err := c.csrfToken(form, page)
+		if err != nil {
+			return err
+		}
+		if form.CSRFField != "" {
+			values.Set(form.CSRFField, token)
+		}
+		if form.CSRFHeader != "" {
+			options = append(options, WithHeader(form.CSRFHeader, token))
+		}
+	}
+
+	// submit
+	var result []byte
+	options = append(options, WithRawReceiver(&result))
+	err := c.request(ctx, http.MethodPost, form.SubmitPath, options)
+	var httpErr *client.HTTPError
+	switch {
+	case errors.As(err, &httpErr) && errors.Is(err, client.ErrRedirect):
+		if c.isLoginPage(form, httpErr.Header.Get("Location")) {
+			return fmt.Errorf("%w: redirected back to login page", client.ErrLoginFailed)
+		}
+		return nil
+	case errors.Is(err, client.ErrUnauthorized) || errors.Is(err, client.ErrForbidden):
+		return fmt.Errorf("%w: %w", client.ErrLoginFailed, err)
+	case err != nil:
+		return err
+	}
+
+	// rejected forms usually render again
+	if form.FailureMarker != "" && strings.Contains(string(result), form.FailureMarker) {
+		return fmt.Errorf("%w: %s", client.ErrLoginFailed, form.FailureMarker)
+	}
+	if client.ContainsInput(result, form.PasswordField) {
+		return fmt.Errorf("%w: login form shown again", client.ErrLoginFailed)
+	}
+
+	return nil
+}
+
+func (c Client) csrfToken(form FormLogin, page []byte) (string, error) {
+
+	// page
+	if token, ok := client.ExtractCSRFToken(page, form.CSRFField, form.CSRFMeta); ok {
+		return token, nil
+	}
+
+	// cookie
+	if form.CSRFCookie != "" {
+		u, err := url.Parse(c.endpoint(form.LoginPage))
+		if err != nil {
+			return "", err
+		}
+		for _, cookie := range c.jar.Cookies(u) {
+			if cookie.Name == form.CSRFCookie {
+				return cookie.Value, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no csrf token found on login page")
+}
+
+func (c Client) isLoginPage(form FormLogin, location string) bool {
+	if location == "" {
+		return false
+	}
+	loginURL, err := url.Parse(c.endpoint(form.LoginPage))
+	if err != nil {
+		return false
+	}
+	target, err := loginURL.Parse(location)
+	if err != nil {
+		return false
+	}
+	return target.Host == loginURL.Host && strings.TrimSuffix(target.Path, "/") == strings.TrimSuffix(loginURL.Path, "/")
+}
+
+// relogin signs in again if err shows the session a request ran in has expired, reporting if to retry or
+// else the error to return
+func (c Client) relogin(ctx context.Context, session int, err error) (bool, error) {
+
+	form, _ := c.login.current()
+	if form == nil {
+		return false, err
+	}
+
+	// detect bounce
+	var httpErr *client.HTTPError
+	bounced := errors.Is(err, client.ErrSessionExpired)
+	if !bounced && errors.As(err, &httpErr) {
+		bounced = httpErr.StatusCode == http.StatusUnauthorized ||
+			errors.Is(httpErr, client.ErrRedirect) && c.isLoginPage(*form, httpErr.Header.Get("Location"))
+		if bounced {
+			err = fmt.Errorf("%w: %w", client.ErrSessionExpired, err)
+		}
+	}
+	if !bounced {
+		return false, err
+	}
+
+	// sign in once at a time, unless another request already did
+	c.login.signIn.Lock()
+	defer c.login.signIn.Unlock()
+	form, current := c.login.current()
+	if current != session {
+		return true, nil
+	}
+	if loginErr := c.submitLogin(ctx, *form); loginErr != nil {
+		if !errors.Is(loginErr, client.ErrLoginFailed) {
+			loginErr = fmt.Errorf("%w: %w", client.ErrLoginFailed, loginErr)
+		}
+		return false, fmt.Errorf("%w, after %w", loginErr, err)
+	}
+	c.login.signedIn(*form)
+
+	return true, nil
+}
+
+// sessionOptions adds the login guard to a request's options and reports the session it runs in
+func (c Client) sessionOptions(options []client.RequestOption) ([]client.RequestOption, int) {
+	form, session := c.login.current()
+	if form == nil {
+		return options, session
+	}
+	return append(options[:len(options):len(options)], c.loginGuard(*form)), session
+}
+
+// loginGuard fails responses that followed redirects to the login page
+func (c Client) loginGuard(form FormLogin) client.RequestOption {
+	return WithResponseProcessor(func(resp *http.Response) error {
+		if resp.Request == nil || len(client.RedirectChain(resp)) == 0 {
+			return nil
+		}
+		if c.isLoginPage(form, resp.Request.URL.String()) {
+			return fmt.Errorf("%w: redirected to login page", client.ErrSessionExpired)
+		}
+		return nil
+	})
+}
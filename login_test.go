@@ -0,0 +1,222 @@
+package apimate
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type testLoginServer struct {
+	*httptest.Server
+	logins  atomic.Int32
+	session atomic.Int32
+	locked  atomic.Bool
+}
+
+// expire invalidates the current session cookie
+func (s *testLoginServer) expire() {
+	s.session.Add(1)
+}
+
+func newLoginServer(t *testing.T) *testLoginServer {
+	s := &testLoginServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			if r.Method == http.MethodGet {
+				http.SetCookie(w, &http.Cookie{Name: "csrftoken", Value: "cookie-token", Path: "/"})
+				_, _ = fmt.Fprint(w, `<form><input type="hidden" name="_csrf" value="page-token"><input name="password"></form>`)
+				return
+			}
+			validToken := r.PostFormValue("_csrf") == "page-token" || r.PostFormValue("csrf") == "cookie-token"
+			if !validToken || r.PostFormValue("username") != "user" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			password := r.PostFormValue("password")
+			if s.locked.Load() {
+				password = "locked"
+			}
+			switch password {
+			case "secret":
+				s.logins.Add(1)
+				http.SetCookie(w, &http.Cookie{Name: "sid", Value: fmt.Sprint(s.session.Add(1)), Path: "/"})
+				http.Redirect(w, r, "/home", http.StatusFound)
+			case "bounce":
+				http.Redirect(w, r, "/login?error=1", http.StatusFound)
+			case "marker":
+				_, _ = fmt.Fprint(w, "Invalid password")
+			case "form":
+				_, _ = fmt.Fprint(w, `<form><input name="password"></form>`)
+			case "unauthorized":
+				w.WriteHeader(http.StatusUnauthorized)
+			default:
+				w.WriteHeader(http.StatusForbidden)
+			}
+		case "/home", "/data", "/api":
+			cookie, err := r.Cookie("sid")
+			if err == nil && cookie.Value == fmt.Sprint(s.session.Load()) {
+				_, _ = fmt.Fprint(w, "ok")
+				return
+			}
+			if r.URL.Path == "/api" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.Redirect(w, r, "/login", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func testLoginForm(password string) FormLogin {
+	return FormLogin{
+		LoginPage:     "login",
+		Username:      "user",
+		Password:      password,
+		CSRFField:     "_csrf",
+		FailureMarker: "Invalid password",
+	}
+}
+
+func TestLoginFailures(t *testing.T) {
+
+	server := newLoginServer(t)
+	c, err := NewSession(server.URL, "")
+	assert.NoError(t, err)
+
+	for _, password := range []string{"bounce", "marker", "form", "unauthorized", "forbidden"} {
+		err := c.Login(testLoginForm(password))
+		assert.True(t, errors.Is(err, ErrLoginFailed), password)
+	}
+	assert.Equal(t, int32(0), server.logins.Load())
+
+	// sessions only
+	assert.Error(t, New(server.URL).Login(testLoginForm("secret")))
+
+	// csrf token from cookie
+	form := testLoginForm("secret")
+	form.CSRFField, form.CSRFCookie = "csrf", "csrftoken"
+	assert.NoError(t, c.Login(form))
+
+	// missing csrf token
+	form.CSRFCookie = "missing"
+	assert.Error(t, c.Login(form))
+
+}
+
+func TestLoginRelogin(t *testing.T) {
+
+	server := newLoginServer(t)
+	c, err := NewSession(server.URL, "")
+	assert.NoError(t, err)
+	assert.NoError(t, c.Login(testLoginForm("secret")))
+
+	request := func(c *Client, ep string) error {
+		var data []byte
+		if err := c.Request(http.MethodGet, ep, WithRawReceiver(&data)); err != nil {
+			return err
+		}
+		assert.Equal(t, "ok", string(data))
+		return nil
+	}
+
+	// logged in
+	assert.NoError(t, request(c, "data"))
+	assert.Equal(t, int32(1), server.logins.Load())
+
+	// bounced by redirect
+	server.expire()
+	assert.NoError(t, request(c, "data"))
+	assert.Equal(t, int32(2), server.logins.Load())
+
+	// bounced by 401
+	server.expire()
+	assert.NoError(t, request(c, "api"))
+	assert.Equal(t, int32(3), server.logins.Load())
+
+	// bounced through followed redirects
+	following, err := NewSession(server.URL, "", WithRedirectPolicy(RedirectPolicy{MaxRedirects: 3}))
+	assert.NoError(t, err)
+	assert.NoError(t, following.Login(testLoginForm("secret")))
+	server.expire()
+	assert.NoError(t, request(following, "data"))
+	assert.Equal(t, int32(5), server.logins.Load())
+
+}
+
+func TestLoginReloginFailure(t *testing.T) {
+
+	server := newLoginServer(t)
+	c, err := NewSession(server.URL, "")
+	assert.NoError(t, err)
+	assert.NoError(t, c.Login(testLoginForm("secret")))
+	following, err := NewSession(server.URL, "", WithRedirectPolicy(RedirectPolicy{MaxRedirects: 3}))
+	assert.NoError(t, err)
+	assert.NoError(t, following.Login(testLoginForm("secret")))
+
+	// account locked meanwhile
+	server.locked.Store(true)
+	server.expire()
+
+	// bounced by redirect
+	err = c.Request(http.MethodGet, "data")
+	assert.True(t, errors.Is(err, ErrSessionExpired))
+	assert.True(t, errors.Is(err, ErrLoginFailed))
+	assert.True(t, errors.Is(err, ErrRedirect))
+
+	// bounced through followed redirects
+	err = following.Request(http.MethodGet, "data")
+	assert.True(t, errors.Is(err, ErrSessionExpired))
+	assert.True(t, errors.Is(err, ErrLoginFailed))
+
+	// other failures untouched
+	err = c.Request(http.MethodGet, "missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrSessionExpired))
+
+}
+
+func TestLoginConcurrentRelogin(t *testing.T) {
+
+	server := newLoginServer(t)
+	c, err := NewSession(server.URL, "")
+	assert.NoError(t, err)
+	assert.NoError(t, c.Login(testLoginForm("secret")))
+
+	// all bounced requests share one new login
+	server.expire()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, c.Request(http.MethodGet, "data"))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), server.logins.Load())
+
+	// logging in while requests are in flight
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, c.Request(http.MethodGet, "data"))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, c.Login(testLoginForm("secret")))
+		}()
+	}
+	wg.Wait()
+
+}
@@ -5,40 +5,68 @@ import (
 )
 
 func WithBearerAuth(apiToken string) client.RequestOption {
-	return func(ctx *client.RequestContext) error {
-		ctx.Req.Header.Set("Authorization", "Bearer "+apiToken)
-		return nil
-	}
+	return WithAuthenticator(client.BearerAuth{Token: apiToken})
 }
 
 func WithHeaderAuth(headerKey, token string) client.RequestOption {
-	return func(ctx *client.RequestContext) error {
-		ctx.Req.Header.Set(headerKey, token)
-		return nil
-	}
+	return WithAuthenticator(client.HeaderAuth{Key: headerKey, Value: token})
 }
 
 func WithBasicAuth(user, pass string) client.RequestOption {
-	return func(ctx *client.RequestContext) error {
-		ctx.Req.SetBasicAuth(user, pass)
-		return nil
-	}
+	return WithAuthenticator(client.BasicAuth{Username: user, Password: pass})
+}
+
+func WithDigestAuth(user, pass string) client.RequestOption {
+	return WithAuthenticator(client.NewDigestAuth(user, pass))
 }
 
 func WithAuthentikAuth(url, clientID, username, password string) client.RequestOption {
+	return WithTokenSource(client.NewAuthentikAuth(url, clientID, username, password))
+}
+
+func WithOAuth2(cfg OAuth2Config) client.RequestOption {
+	return WithTokenSource(NewOAuth2TokenSource(cfg))
+}
+
+func WithHMACSigning(cfg HMACConfig) client.RequestOption {
+	signer, err := client.NewHMACSigner(cfg)
 	return func(ctx *client.RequestContext) error {
-		token, err := client.NewAuthentikAuth(url, clientID, username, password).Authenticate()
 		if err != nil {
 			return err
 		}
-		ctx.Req.Header.Set("Authorization", "Bearer "+token)
+		ctx.Authenticators = append(ctx.Authenticators, signer)
+		return nil
+	}
+}
+
+func WithSigV4(accessKey, secretKey, sessionToken, region, service string) client.RequestOption {
+	return WithAuthenticator(client.NewSigV4Signer(accessKey, secretKey, sessionToken, region, service))
+}
+
+func WithSigV4UnsignedPayload(accessKey, secretKey, sessionToken, region, service string) client.RequestOption {
+	signer := client.NewSigV4Signer(accessKey, secretKey, sessionToken, region, service)
+	signer.UnsignedPayload = true
+	return WithAuthenticator(signer)
+}
+
+func WithTokenSource(ts TokenSource) client.RequestOption {
+	return WithAuthenticator(client.TokenAuth{Source: ts})
+}
+
+func WithAuthenticator(auth Authenticator) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.Authenticators = append(ctx.Authenticators, auth)
 		return nil
 	}
 }
 
+func NewOAuth2TokenSource(cfg OAuth2Config) *OAuth2TokenSource {
+	return client.NewOAuth2TokenSource(cfg)
+}
+
 func WithTlsSkipVerify(skip bool) client.RequestOption {
 	return func(ctx *client.RequestContext) error {
-		ctx.SkipTLSVerify = skip
+		ctx.Transport.SkipTLSVerify = skip
 		return nil
 	}
 }
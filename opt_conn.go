@@ -0,0 +1,204 @@
+package apimate
+
+import (
+	"fmt"
+	"github.com/rollicks-c/apimate/internal/client"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WithHTTPClient sends requests through httpClient; TLS and connection options cannot be combined with it
+func WithHTTPClient(httpClient *http.Client) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.HTTPClient = httpClient
+		return nil
+	}
+}
+
+// WithTransport sends requests through transport; TLS and connection options cannot be combined with it
+func WithTransport(transport http.RoundTripper) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.RoundTripper = transport
+		return nil
+	}
+}
+
+func WithClientCertificate(certPEM, keyPEM []byte) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.Transport.ClientCertPEM = string(certPEM)
+		ctx.Transport.ClientKeyPEM = string(keyPEM)
+		return nil
+	}
+}
+
+func WithClientCertificateFiles(certFile, keyFile string) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		certPEM, err := os.ReadFile(certFile)
+		if err != nil {
+			return err
+		}
+		keyPEM, err := os.ReadFile(keyFile)
+		if err != nil {
+			return err
+		}
+		return WithClientCertificate(certPEM, keyPEM)(ctx)
+	}
+}
+
+func WithRootCAs(pem []byte) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.Transport.RootCAsPEM += string(pem) + "\n"
+		return nil
+	}
+}
+
+func WithRootCAFile(caFile string) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return err
+		}
+		return WithRootCAs(pem)(ctx)
+	}
+}
+
+func WithPinnedCertificates(sha256Fingerprints ...string) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		pins := make([]string, 0, len(sha256Fingerprints))
+		for _, fingerprint := range sha256Fingerprints {
+			pins = append(pins, client.NormalizeFingerprint(fingerprint))
+		}
+		sort.Strings(pins)
+		ctx.Transport.PinnedSHA256 = strings.Join(pins, ",")
+		return nil
+	}
+}
+
+func WithMinTLSVersion(version uint16) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.Transport.MinTLSVersion = version
+		return nil
+	}
+}
+
+func WithRedirectPolicy(policy RedirectPolicy) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		if policy.MaxRedirects < 0 {
+			return fmt.Errorf("invalid redirect limit: %d", policy.MaxRedirects)
+		}
+		ctx.RedirectPolicy = policy
+		return nil
+	}
+}
+
+// RedirectChain returns the redirects that led to a response, e.g. inside a response processor
+func RedirectChain(resp *http.Response) []*http.Response {
+	return client.RedirectChain(resp)
+}
+
+// WithTimeout bounds the whole request, including retries, waits and pages
+func WithTimeout(timeout time.Duration) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid timeout: %s", timeout)
+		}
+		ctx.Timeout = timeout
+		return nil
+	}
+}
+
+// WithAttemptTimeout bounds every single attempt, including reading its response; timed out attempts are retried
+func WithAttemptTimeout(timeout time.Duration) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid attempt timeout: %s", timeout)
+		}
+		ctx.AttemptTimeout = timeout
+		return nil
+	}
+}
+
+func WithDialTimeout(timeout time.Duration) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.Transport.DialTimeout = timeout
+		return nil
+	}
+}
+
+func WithTLSHandshakeTimeout(timeout time.Duration) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.Transport.TLSHandshakeTimeout = timeout
+		return nil
+	}
+}
+
+func WithResponseHeaderTimeout(timeout time.Duration) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.Transport.ResponseHeaderTimeout = timeout
+		return nil
+	}
+}
+
+// WithRateLimit shares one token bucket among all requests given this option, e.g. as a client default;
+// retries and pages draw from it too
+func WithRateLimit(perSecond float64, burst int) client.RequestOption {
+	bucket := client.NewTokenBucket(perSecond, burst)
+	return func(ctx *client.RequestContext) error {
+		if err := validateRateLimit(perSecond, burst); err != nil {
+			return err
+		}
+		return WithRateLimiter(bucket)(ctx)
+	}
+}
+
+// WithHostRateLimit is like WithRateLimit, with a separate bucket for each host
+func WithHostRateLimit(perSecond float64, burst int) client.RequestOption {
+	limiter := client.NewHostRateLimiter(perSecond, burst)
+	return func(ctx *client.RequestContext) error {
+		if err := validateRateLimit(perSecond, burst); err != nil {
+			return err
+		}
+		return WithRateLimiter(limiter)(ctx)
+	}
+}
+
+// WithEndpointRateLimit is like WithRateLimit, limiting only endpoints matching pattern (e.g. "search/*")
+func WithEndpointRateLimit(pattern string, perSecond float64, burst int) client.RequestOption {
+	bucket := client.NewTokenBucket(perSecond, burst)
+	return func(ctx *client.RequestContext) error {
+		if err := validateRateLimit(perSecond, burst); err != nil {
+			return err
+		}
+
+		// match relative to the api url
+		base, err := url.Parse(ctx.ApiUrl)
+		if err != nil {
+			return err
+		}
+		absolute := fmt.Sprintf("%s/%s", strings.TrimSuffix(base.Path, "/"), strings.TrimPrefix(pattern, "/"))
+		if _, err := path.Match(absolute, ""); err != nil {
+			return fmt.Errorf("invalid endpoint pattern %q: %w", pattern, err)
+		}
+
+		return WithRateLimiter(client.EndpointRateLimiter{Pattern: absolute, Bucket: bucket})(ctx)
+	}
+}
+
+func WithRateLimiter(limiter RateLimiter) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.RateLimiters = append(ctx.RateLimiters, limiter)
+		return nil
+	}
+}
+
+func validateRateLimit(perSecond float64, burst int) error {
+	if perSecond <= 0 || burst < 1 {
+		return fmt.Errorf("invalid rate limit: %g/s, burst %d", perSecond, burst)
+	}
+	return nil
+}
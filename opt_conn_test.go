@@ -0,0 +1,84 @@
+package apimate
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newConnCountingServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Via")))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, &conns
+}
+
+func TestConnectionReuse(t *testing.T) {
+
+	server, conns := newConnCountingServer(t)
+
+	// one connection across requests of a client
+	c := New(server.URL)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, c.Request(http.MethodGet, "ping"))
+	}
+	assert.Equal(t, int32(1), conns.Load())
+
+	// separate pool per client
+	assert.NoError(t, New(server.URL).Request(http.MethodGet, "ping"))
+	assert.Equal(t, int32(2), conns.Load())
+
+	// released on request
+	c.CloseIdleConnections()
+	assert.NoError(t, c.Request(http.MethodGet, "ping"))
+	assert.Equal(t, int32(3), conns.Load())
+
+}
+
+func TestCustomTransport(t *testing.T) {
+
+	server, _ := newConnCountingServer(t)
+	c := New(server.URL)
+
+	// round tripper
+	var trips atomic.Int32
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		trips.Add(1)
+		req.Header.Set("X-Via", "transport")
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	var data []byte
+	assert.NoError(t, c.Request(http.MethodGet, "ping", WithTransport(transport), WithRawReceiver(&data)))
+	assert.Equal(t, "transport", string(data))
+	assert.Equal(t, int32(1), trips.Load())
+
+	// http client
+	httpClient := &http.Client{Transport: transport, Timeout: time.Second}
+	assert.NoError(t, c.Request(http.MethodGet, "ping", WithHTTPClient(httpClient), WithRawReceiver(&data)))
+	assert.Equal(t, "transport", string(data))
+	assert.Equal(t, int32(2), trips.Load())
+
+	// transport settings would be ignored
+	assert.Error(t, c.Request(http.MethodGet, "ping", WithHTTPClient(httpClient), WithTlsSkipVerify(true)))
+	assert.Error(t, c.Request(http.MethodGet, "ping", WithTransport(transport), WithDialTimeout(time.Second)))
+	assert.Equal(t, int32(2), trips.Load())
+
+}
@@ -22,6 +22,17 @@ func WithCookie(name string, value string) client.RequestOption {
 	}
 }
 
+func WithCookieJar(jar http.CookieJar) client.RequestOption {
+	return func(ctx *client.RequestContext) error {
+		ctx.Jar = jar
+		return nil
+	}
+}
+
+func NewCookieJar(path string) (*CookieJar, error) {
+	return client.NewCookieJar(path)
+}
+
 func WithHeaders(Headers http.Header) client.RequestOption {
 	return func(ctx *client.RequestContext) error {
 		for key, values := range Headers {
@@ -41,7 +52,7 @@ func WithHeader(key, value string) client.RequestOption {
 
 func WithDefaultRequest() client.RequestOption {
 	return func(ctx *client.RequestContext) error {
-		req, err := http.NewRequest(ctx.Method, ctx.Endpoint, nil)
+		req, err := http.NewRequestWithContext(ctx.Context, ctx.Method, ctx.Endpoint, nil)
 		if err != nil {
 			return err
 		}
@@ -52,7 +63,7 @@ func WithDefaultRequest() client.RequestOption {
 
 func WithPayload(body io.Reader) client.RequestOption {
 	return func(ctx *client.RequestContext) error {
-		req, err := http.NewRequest(ctx.Method, ctx.Endpoint, body)
+		req, err := http.NewRequestWithContext(ctx.Context, ctx.Method, ctx.Endpoint, body)
 		if err != nil {
 			return err
 		}
@@ -63,7 +74,7 @@ func WithPayload(body io.Reader) client.RequestOption {
 
 func WithFormPayload(body io.Reader) client.RequestOption {
 	return func(ctx *client.RequestContext) error {
-		req, err := http.NewRequest(ctx.Method, ctx.Endpoint, body)
+		req, err := http.NewRequestWithContext(ctx.Context, ctx.Method, ctx.Endpoint, body)
 		if err != nil {
 			return err
 		}
@@ -82,7 +93,7 @@ func WithJSONPayload(data any) client.RequestOption {
 		}
 
 		body := bytes.NewReader(raw)
-		req, err := http.NewRequest(ctx.Method, ctx.Endpoint, body)
+		req, err := http.NewRequestWithContext(ctx.Context, ctx.Method, ctx.Endpoint, body)
 		if err != nil {
 			return err
 		}
@@ -94,7 +105,7 @@ func WithJSONPayload(data any) client.RequestOption {
 
 func WithValues(values url.Values) client.RequestOption {
 	return func(ctx *client.RequestContext) error {
-		req, err := http.NewRequest(ctx.Method, ctx.Endpoint, strings.NewReader(values.Encode()))
+		req, err := http.NewRequestWithContext(ctx.Context, ctx.Method, ctx.Endpoint, strings.NewReader(values.Encode()))
 		if err != nil {
 			return err
 		}
@@ -138,7 +149,7 @@ func WithFilePayload(name string, data []byte) client.RequestOption {
 		_ = writer.Close()
 
 		// create request
-		req, err := http.NewRequest(ctx.Method, ctx.Endpoint, body)
+		req, err := http.NewRequestWithContext(ctx.Context, ctx.Method, ctx.Endpoint, body)
 		if err != nil {
 			return err
 		}
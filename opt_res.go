@@ -1,7 +1,6 @@
 package apimate
 
 import (
-	"bytes"
 	"encoding/json"
 	"encoding/xml"
 	"github.com/rollicks-c/apimate/internal/client"
@@ -32,6 +31,10 @@ func WithResponseProcessor(proc client.ResponseProcessor) client.RequestOption {
 }
 
 func WithJSONReceiver(receiver interface{}) client.RequestOption {
+	return WithJSONItemsReceiver("", receiver)
+}
+
+func WithJSONItemsReceiver(itemsPath string, receiver interface{}) client.RequestOption {
 	return func(ctx *client.RequestContext) error {
 		ctx.Req.Header.Set("Content-Type", "application/json")
 		ctx.Receiver = func(payload [][]byte) error {
@@ -41,10 +44,9 @@ func WithJSONReceiver(receiver interface{}) client.RequestOption {
 				return nil
 			}
 
-			// paged
-			if len(payload) > 1 {
-				merged := bytes.Join(payload, []byte("\n"))
-				data, err := client.ParseArrayList(merged)
+			// paged or enveloped
+			if len(payload) > 1 || itemsPath != "" {
+				data, err := client.MergeJSONPages(payload, itemsPath)
 				if err != nil {
 					return err
 				}
@@ -73,8 +75,7 @@ func WithXMLReceiver(receiver interface{}) client.RequestOption {
 
 			// paged
 			if len(payload) > 1 {
-				merged := bytes.Join(payload, []byte("\n"))
-				data, err := client.ParseArrayList(merged)
+				data, err := client.MergeXMLPages(payload)
 				if err != nil {
 					return err
 				}
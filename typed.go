@@ -0,0 +1,67 @@
+package apimate
+
+import (
+	"context"
+	"github.com/rollicks-c/apimate/internal/client"
+	"net/http"
+)
+
+func Get[T any](c *Client, ep string, options ...client.RequestOption) (T, error) {
+	return GetCtx[T](context.Background(), c, ep, options...)
+}
+
+func GetCtx[T any](ctx context.Context, c *Client, ep string, options ...client.RequestOption) (T, error) {
+	return requestJSON[T](ctx, c, http.MethodGet, ep, options)
+}
+
+func Delete[T any](c *Client, ep string, options ...client.RequestOption) (T, error) {
+	return DeleteCtx[T](context.Background(), c, ep, options...)
+}
+
+func DeleteCtx[T any](ctx context.Context, c *Client, ep string, options ...client.RequestOption) (T, error) {
+	return requestJSON[T](ctx, c, http.MethodDelete, ep, options)
+}
+
+func Post[Req, Res any](c *Client, ep string, payload Req, options ...client.RequestOption) (Res, error) {
+	return PostCtx[Req, Res](context.Background(), c, ep, payload, options...)
+}
+
+func PostCtx[Req, Res any](ctx context.Context, c *Client, ep string, payload Req, options ...client.RequestOption) (Res, error) {
+	return requestJSON[Res](ctx, c, http.MethodPost, ep, withPayload(payload, options))
+}
+
+func Put[Req, Res any](c *Client, ep string, payload Req, options ...client.RequestOption) (Res, error) {
+	return PutCtx[Req, Res](context.Background(), c, ep, payload, options...)
+}
+
+func PutCtx[Req, Res any](ctx context.Context, c *Client, ep string, payload Req, options ...client.RequestOption) (Res, error) {
+	return requestJSON[Res](ctx, c, http.MethodPut, ep, withPayload(payload, options))
+}
+
+func Patch[Req, Res any](c *Client, ep string, payload Req, options ...client.RequestOption) (Res, error) {
+	return PatchCtx[Req, Res](context.Background(), c, ep, payload, options...)
+}
+
+func PatchCtx[Req, Res any](ctx context.Context, c *Client, ep string, payload Req, options ...client.RequestOption) (Res, error) {
+	return requestJSON[Res](ctx, c, http.MethodPatch, ep, withPayload(payload, options))
+}
+
+func withPayload(payload any, options []client.RequestOption) []client.RequestOption {
+	// payload replaces the request, so it must run before any option touching it
+	return append([]client.RequestOption{WithJSONPayload(payload)}, options...)
+}
+
+func requestJSON[T any](ctx context.Context, c *Client, method, ep string, options []client.RequestOption) (T, error) {
+
+	// decode into result, merging pages if any
+	var result T
+	options = append(options[:len(options):len(options)], WithJSONReceiver(&result))
+
+	// execute
+	if err := c.RequestCtx(ctx, method, ep, options...); err != nil {
+		var empty T
+		return empty, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package apimate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+type testUser struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Method string `json:"method"`
+}
+
+func TestTypedPagedSlice(t *testing.T) {
+
+	server, fetched := newItemServer(t, 3, 0)
+	c := New(server.URL)
+
+	// merged across pages
+	items, err := Get[[]testItem](c, "items", WithLinkHeaderPaging())
+	assert.NoError(t, err)
+	assert.Equal(t, []testItem{{1}, {2}, {3}, {4}, {5}, {6}}, items)
+	assert.Equal(t, int32(3), fetched.Load())
+
+	// typed receiver wins over the caller's
+	var raw []byte
+	items, err = Get[[]testItem](c, "items", WithRawReceiver(&raw))
+	assert.NoError(t, err)
+	assert.Equal(t, []testItem{{1}, {2}}, items)
+	assert.Empty(t, raw)
+
+	// zero value on error
+	failing, _ := newItemServer(t, 3, 1)
+	items, err = Get[[]testItem](New(failing.URL), "items")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Nil(t, items)
+
+}
+
+func TestTypedRoundTrip(t *testing.T) {
+
+	// echo users, assigning an id
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user testUser
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&user)
+		}
+		if r.Header.Get("Content-Type") != "application/json" && r.Method != http.MethodDelete {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		user.ID = 7
+		user.Method = r.Method
+		_ = json.NewEncoder(w).Encode(user)
+	}))
+	defer server.Close()
+	c := New(server.URL)
+
+	created, err := Post[testUser, testUser](c, "users", testUser{Name: "ada"})
+	assert.NoError(t, err)
+	assert.Equal(t, testUser{ID: 7, Name: "ada", Method: http.MethodPost}, created)
+
+	updated, err := Put[testUser, testUser](c, "users/7", testUser{Name: "grace"})
+	assert.NoError(t, err)
+	assert.Equal(t, testUser{ID: 7, Name: "grace", Method: http.MethodPut}, updated)
+
+	patched, err := Patch[map[string]string, testUser](c, "users/7", map[string]string{"name": "alan"})
+	assert.NoError(t, err)
+	assert.Equal(t, testUser{ID: 7, Name: "alan", Method: http.MethodPatch}, patched)
+
+	deleted, err := Delete[testUser](c, "users/7")
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodDelete, deleted.Method)
+
+}
+
+func TestTypedOffsetPagingDefault(t *testing.T) {
+
+	// five items, wrapped in an object
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var items []testItem
+		for id := offset + 1; id <= min(offset+limit, 5); id++ {
+			items = append(items, testItem{ID: id})
+		}
+		w.Header().Set("X-Total-Count", "5")
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+	}))
+	defer server.Close()
+
+	// paging configured once for the client
+	c := New(server.URL, WithOffsetPaging("offset", "limit", 2, OffsetTotal("X-Total-Count", ""), OffsetItems("items")))
+	items, err := Get[[]testItem](c, "items", WithConcurrentPages(2))
+	assert.NoError(t, err)
+	assert.Equal(t, []testItem{{1}, {2}, {3}, {4}, {5}}, items)
+
+}
+
+func TestTypedCancellation(t *testing.T) {
+
+	server, fetched := newItemServer(t, 3, 0)
+	c := New(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	items, err := GetCtx[[]testItem](ctx, c, "items", WithLinkHeaderPaging())
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Nil(t, items)
+
+	created, err := PostCtx[testItem, testItem](ctx, c, "items", testItem{ID: 1})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, testItem{}, created)
+
+	assert.Equal(t, int32(0), fetched.Load())
+
+}
+
+func TestTypedConcurrentPagesValidation(t *testing.T) {
+
+	server, fetched := newItemServer(t, 3, 0)
+	c := New(server.URL)
+
+	_, err := Get[[]testItem](c, "items", WithConcurrentPages(0))
+	assert.ErrorContains(t, err, "invalid page concurrency")
+	assert.Equal(t, int32(0), fetched.Load())
+
+}